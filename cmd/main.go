@@ -1,58 +1,45 @@
 package main
 
 import (
-	"net"
+	"flag"
+	"fmt"
+	"os"
 
-	"github.com/PharmaKart/authentication-svc/internal/handlers"
-	pb "github.com/PharmaKart/authentication-svc/internal/proto"
-	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/internal/commands"
 	"github.com/PharmaKart/authentication-svc/pkg/config"
 	"github.com/PharmaKart/authentication-svc/pkg/utils"
-
-	"google.golang.org/grpc"
 )
 
 func main() {
-	// Load configuration
-	cfg := config.LoadConfig()
-
-	// Initialize logger
-	utils.InitLogger()
-
-	// Initialize database connection
-	db, err := utils.ConnectDB(cfg)
-	if err != nil {
-		utils.Logger.Fatal("Failed to connect to database", map[string]interface{}{
-			"error": err,
-		})
+	// Default to serving when no command is given
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
 	}
 
-	// Initialize repositories
-	userRepo := repositories.NewUserRepository(db)
-	customerRepo := repositories.NewCustomerRepository(db)
-
-	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(userRepo, customerRepo, cfg.JWTSecret)
-
-	// Initialize gRPC server
-	lis, err := net.Listen("tcp", ":"+cfg.Port)
+	if name == "help" || name == "-h" || name == "--help" {
+		commands.PrintUsage(os.Stdout)
+		return
+	}
 
-	if err != nil {
-		utils.Logger.Fatal("Failed to listen", map[string]interface{}{
-			"error": err,
-		})
+	cmd, ok := commands.Lookup(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		commands.PrintUsage(os.Stderr)
+		os.Exit(2)
 	}
 
-	grpcServer := grpc.NewServer()
-	pb.RegisterAuthServiceServer(grpcServer, authHandler)
+	// Load configuration
+	cfg := config.LoadConfig()
 
-	utils.Info("Starting authentication service", map[string]interface{}{
-		"port": cfg.Port,
-	})
+	// Initialize logger
+	utils.InitLogger()
 
-	if err := grpcServer.Serve(lis); err != nil {
-		utils.Logger.Fatal("Failed to serve", map[string]interface{}{
-			"error": err,
-		})
+	if err := cmd.Run(cfg, args); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "Error:", commands.FormatError(err))
+		os.Exit(1)
 	}
 }
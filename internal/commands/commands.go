@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/internal/eligibility"
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/outbox"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/internal/webhook"
+	"github.com/PharmaKart/authentication-svc/pkg/config"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"golang.org/x/term"
+	"gorm.io/gorm"
+)
+
+// Command is a subcommand of the service binary
+type Command struct {
+	Name        string
+	Usage       string
+	Description string
+	Run         func(cfg *config.Config, args []string) error
+}
+
+// App holds the dependencies shared by all commands
+type App struct {
+	Config         *config.Config
+	DB             *gorm.DB
+	UserRepo       repositories.UserRepository
+	CustomerRepo   repositories.CustomerRepository
+	SigningKeyRepo repositories.SigningKeyRepository
+	RoleRepo       repositories.RoleRepository
+	DecisionRepo   repositories.AuthorizationDecisionRepository
+	InvitationRepo repositories.InvitationRepository
+	CredentialRepo repositories.CredentialRepository
+	DelegationRepo repositories.DelegationRepository
+	AddressRepo    repositories.AddressRepository
+	DeletionRepo   repositories.AccountDeletionRepository
+	AuditRepo      repositories.AuditRepository
+	ExportRepo     repositories.DataExportRepository
+	ConsentRepo    repositories.ConsentRepository
+	OutboxRepo     repositories.OutboxRepository
+	WebhookRepo    repositories.WebhookRepository
+
+	AuthService        services.AuthService
+	AdminService       services.AdminService
+	RoleService        services.RoleService
+	InvitationService  services.InvitationService
+	CredentialService  services.CredentialService
+	DelegationService  services.DelegationService
+	EligibilityService services.EligibilityService
+	ProfileService     services.ProfileService
+	AddressService     services.AddressService
+	ErasureService     services.ErasureService
+	ExportService      services.ExportService
+	ConsentService     services.ConsentService
+	AuditService       services.AuditService
+	OutboxService      services.OutboxService
+	WebhookService     services.WebhookService
+}
+
+var registry = map[string]*Command{}
+
+func register(cmd *Command) {
+	registry[cmd.Name] = cmd
+}
+
+// Lookup returns the command with the given name
+func Lookup(name string) (*Command, bool) {
+	cmd, ok := registry[name]
+	return cmd, ok
+}
+
+// NewApp connects to the database and wires the repositories and services
+func NewApp(cfg *config.Config) (*App, error) {
+	// Initialize database connection
+	db, err := utils.ConnectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create missing tables and columns
+	if err := utils.MigrateDB(db); err != nil {
+		return nil, err
+	}
+
+	// Initialize repositories
+	userRepo := repositories.NewUserRepository(db)
+	customerRepo := repositories.NewCustomerRepository(db)
+	signingKeyRepo := repositories.NewSigningKeyRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	decisionRepo := repositories.NewAuthorizationDecisionRepository(db)
+	invitationRepo := repositories.NewInvitationRepository(db)
+	credentialRepo := repositories.NewCredentialRepository(db)
+	delegationRepo := repositories.NewDelegationRepository(db)
+	addressRepo := repositories.NewAddressRepository(db)
+	deletionRepo := repositories.NewAccountDeletionRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	exportRepo := repositories.NewDataExportRepository(db)
+	consentRepo := repositories.NewConsentRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+
+	// Create the default roles and permissions
+	if err := roleRepo.SeedDefaults(); err != nil {
+		return nil, err
+	}
+
+	// Create the first version of policies that have none
+	if err := consentRepo.SeedPolicies(models.DefaultPolicies); err != nil {
+		return nil, err
+	}
+
+	// Load the age restrictions
+	ageRules, err := eligibility.Load(cfg.EligibilityFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the sink domain events are published to
+	sink, err := outbox.New(cfg.EventSink, cfg.EventSinkTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize services
+	eligibilityService := services.NewEligibilityService(customerRepo, delegationRepo, addressRepo, ageRules)
+	auditService := services.NewAuditService(auditRepo)
+	consentService := services.NewConsentService(consentRepo)
+	authService := services.NewAuthService(userRepo, customerRepo, signingKeyRepo, roleRepo, credentialRepo, delegationRepo, eligibilityService, consentService, auditService, cfg.MinRegistrationAge, cfg.JWTSecret)
+	erasureService := services.NewErasureService(userRepo, deletionRepo, auditService, cfg.AccountDeletionGracePeriod)
+	adminService := services.NewAdminService(userRepo, roleRepo, authService, erasureService, auditService)
+	roleService := services.NewRoleService(userRepo, roleRepo, auditService)
+	invitationService := services.NewInvitationService(invitationRepo, userRepo, roleRepo, auditService, cfg.InvitationTTL)
+	credentialService := services.NewCredentialService(credentialRepo, roleRepo)
+	delegationService := services.NewDelegationService(delegationRepo, authService, auditService)
+	profileService := services.NewProfileService(userRepo, customerRepo)
+	addressService := services.NewAddressService(customerRepo, addressRepo)
+	webhookService := services.NewWebhookService(webhookRepo, webhook.NewClient(cfg.WebhookTimeout), cfg.WebhookMaxAttempts, cfg.OutboxRetention)
+	// Events go to the sink and are queued for the webhook subscriptions
+	outboxService := services.NewOutboxService(outboxRepo, outbox.NewMultiPublisher(sink, webhookService), cfg.OutboxMaxAttempts, cfg.OutboxRetention)
+	exportService := services.NewExportService(exportRepo, userRepo, customerRepo, addressRepo, roleRepo, delegationRepo, credentialRepo, deletionRepo, auditRepo, consentRepo, cfg.DataExportTTL)
+
+	return &App{
+		Config:         cfg,
+		DB:             db,
+		UserRepo:       userRepo,
+		CustomerRepo:   customerRepo,
+		SigningKeyRepo: signingKeyRepo,
+		RoleRepo:       roleRepo,
+		DecisionRepo:   decisionRepo,
+		InvitationRepo: invitationRepo,
+		CredentialRepo: credentialRepo,
+		DelegationRepo: delegationRepo,
+		AddressRepo:    addressRepo,
+		DeletionRepo:   deletionRepo,
+		AuditRepo:      auditRepo,
+		ExportRepo:     exportRepo,
+		ConsentRepo:    consentRepo,
+		OutboxRepo:     outboxRepo,
+		WebhookRepo:    webhookRepo,
+
+		AuthService:        authService,
+		AdminService:       adminService,
+		RoleService:        roleService,
+		InvitationService:  invitationService,
+		CredentialService:  credentialService,
+		DelegationService:  delegationService,
+		EligibilityService: eligibilityService,
+		ProfileService:     profileService,
+		AddressService:     addressService,
+		ErasureService:     erasureService,
+		ExportService:      exportService,
+		ConsentService:     consentService,
+		AuditService:       auditService,
+		OutboxService:      outboxService,
+		WebhookService:     webhookService,
+	}, nil
+}
+
+// PrintUsage writes the list of available commands
+func PrintUsage(w io.Writer) {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: auth <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, registry[name].Description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run without a command to start the server.")
+}
+
+// FormatError renders an error for the terminal, including the details of application errors
+func FormatError(err error) string {
+	appErr, ok := errors.IsAppError(err)
+	if !ok || len(appErr.Details) == 0 {
+		return err.Error()
+	}
+
+	keys := make([]string, 0, len(appErr.Details))
+	for k := range appErr.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(appErr.Message)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n  %s: %s", k, appErr.Details[k])
+	}
+	return b.String()
+}
+
+func newFlagSet(cmd *Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: auth %s %s\n\n%s\n\n", cmd.Name, cmd.Usage, cmd.Description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func requireFlags(values map[string]string) error {
+	var missing []string
+	for name, value := range values {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// readPassword returns the first line of the file, or of standard input when path is empty or "-".
+// On a terminal the password is prompted for without echo, so it never shows up in the shell history or process list.
+func readPassword(path, prompt string) (string, error) {
+	input := os.Stdin
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	if term.IsTerminal(int(input.Fd())) {
+		fmt.Fprint(os.Stderr, prompt)
+		password, err := term.ReadPassword(int(input.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+
+	line, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("no password given")
+	}
+	return password, nil
+}
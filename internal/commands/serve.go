@@ -0,0 +1,402 @@
+package commands
+
+import (
+	"context"
+	goerrors "errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/handlers"
+	"github.com/PharmaKart/authentication-svc/internal/interceptors"
+	"github.com/PharmaKart/authentication-svc/internal/metrics"
+	"github.com/PharmaKart/authentication-svc/internal/policy"
+	pb "github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/internal/tracing"
+	"github.com/PharmaKart/authentication-svc/pkg/config"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var serveCmd = &Command{
+	Name:        "serve",
+	Description: "Start the gRPC server",
+}
+
+func init() {
+	serveCmd.Run = runServe
+
+	register(serveCmd)
+}
+
+func runServe(cfg *config.Config, args []string) error {
+	if err := newFlagSet(serveCmd).Parse(args); err != nil {
+		return err
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Export traces of the calls, continuing the trace context sent by callers, with a span per database query
+	shutdownTracing, err := tracing.Setup(context.Background(), app.Config.TraceExporter, app.Config.TraceExporterTarget)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+	if err := tracing.InstrumentDB(app.DB); err != nil {
+		return err
+	}
+
+	// Background workers run until workerCtx is cancelled on shutdown, which waits for them to return
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	startWorker := func(run func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run()
+		}()
+	}
+
+	// Load the authorization policies and reload them when the file changes
+	policies, err := policy.NewStore(app.Config.PolicyFile)
+	if err != nil {
+		return err
+	}
+	startWorker(func() { policies.Watch(workerCtx, app.Config.PolicyReloadInterval) })
+
+	// Expire lapsed credentials and flag the ones about to lapse
+	startWorker(func() {
+		runCredentialExpiry(workerCtx, app.CredentialService, app.Config.CredentialCheckInterval, app.Config.CredentialExpiryWarning)
+	})
+
+	// Erase the accounts whose deletion grace period has ended
+	startWorker(func() { runAccountErasure(workerCtx, app.ErasureService, app.Config.ErasureCheckInterval) })
+
+	// Run data exports left over from a restart and delete expired ones
+	startWorker(func() { runDataExports(workerCtx, app.ExportService, app.Config.DataExportCheckInterval) })
+
+	// Publish the domain events written to the outbox
+	startWorker(func() { runOutboxRelay(workerCtx, app.OutboxService, app.Config.OutboxRelayInterval) })
+
+	// Send the queued webhook deliveries
+	startWorker(func() { runWebhookDeliveries(workerCtx, app.WebhookService, app.Config.WebhookDeliveryInterval) })
+
+	// Serve the metrics, including the database connection pool statistics
+	sqlDB, err := app.DB.DB()
+	if err != nil {
+		return err
+	}
+	metrics.RegisterDB(sqlDB, "authentication")
+	metricsServer := newMetricsServer(app.Config.MetricsPort)
+	go runMetricsServer(metricsServer)
+
+	authorizationService := services.NewAuthorizationService(app.AuthService, policies, app.DecisionRepo)
+
+	// Initialize handlers. Internal error details are only returned to callers in development.
+	handlers.ExposeInternalErrors(app.Config.DevMode)
+	authHandler := handlers.NewAuthHandler(app.AuthService, authorizationService, app.InvitationService, app.EligibilityService, app.ConsentService)
+	adminHandler := handlers.NewAdminHandler(app.AdminService, app.RoleService, app.InvitationService, app.CredentialService, app.ConsentService, app.AuditService, app.WebhookService)
+	credentialHandler := handlers.NewCredentialHandler(app.CredentialService)
+	delegationHandler := handlers.NewDelegationHandler(app.DelegationService)
+	profileHandler := handlers.NewProfileHandler(app.ProfileService, app.ErasureService, app.ExportService)
+	addressHandler := handlers.NewAddressHandler(app.AddressService)
+	consentHandler := handlers.NewConsentHandler(app.ConsentService)
+
+	// Initialize gRPC server
+	lis, err := net.Listen("tcp", ":"+app.Config.Port)
+	if err != nil {
+		return err
+	}
+
+	// Every call gets a request ID and is logged and measured; panics in handlers fail the call instead of the server
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		interceptors.RequestID(),
+		interceptors.LogCalls(),
+		interceptors.CollectMetrics(),
+		interceptors.RecoverPanics(),
+		interceptors.AuditCalls(app.AuditService, "/auth.AdminService/"),
+		interceptors.RequireRole(app.AuthService, "/auth.AdminService/", "admin"),
+		interceptors.RequireAuthentication(app.AuthService, "/auth.CredentialService/"),
+		interceptors.RequireAuthentication(app.AuthService, "/auth.DelegationService/"),
+		interceptors.RequireAuthentication(app.AuthService, "/auth.ProfileService/"),
+		interceptors.RequireAuthentication(app.AuthService, "/auth.AddressService/"),
+		interceptors.RequireConsentAuthentication(app.AuthService, "/auth.ConsentService/"),
+	}
+	// Legacy clients read failures from the success and error fields of an OK response
+	if !app.Config.LegacyErrorResponses {
+		unaryInterceptors = append(unaryInterceptors, interceptors.StatusErrors())
+	}
+
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		interceptors.StreamRequestID(),
+		interceptors.StreamLogCalls(),
+		interceptors.StreamCollectMetrics(),
+		interceptors.StreamRecoverPanics(),
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	pb.RegisterAuthServiceServer(grpcServer, authHandler)
+	pb.RegisterAdminServiceServer(grpcServer, adminHandler)
+	pb.RegisterCredentialServiceServer(grpcServer, credentialHandler)
+	pb.RegisterDelegationServiceServer(grpcServer, delegationHandler)
+	pb.RegisterProfileServiceServer(grpcServer, profileHandler)
+	pb.RegisterAddressServiceServer(grpcServer, addressHandler)
+	pb.RegisterConsentServiceServer(grpcServer, consentHandler)
+
+	// Report readiness over grpc.health.v1, for the server ("") and each service. Calls are only accepted
+	// once the database answers and a signing key is available.
+	healthServer := health.NewServer()
+	serviceNames := []string{""}
+	for name := range grpcServer.GetServiceInfo() {
+		serviceNames = append(serviceNames, name)
+	}
+	for _, name := range serviceNames {
+		healthServer.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthService := services.NewHealthService(sqlDB, app.SigningKeyRepo, app.Config.JWTSecret)
+	startWorker(func() {
+		runHealthChecks(workerCtx, healthService, healthServer, serviceNames, app.Config.HealthCheckInterval)
+	})
+
+	utils.Info("Starting authentication service", map[string]interface{}{
+		"port": app.Config.Port,
+	})
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(lis)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	var serveFailure error
+	select {
+	case serveFailure = <-serveErr:
+		utils.Error("gRPC server stopped", map[string]interface{}{
+			"error": serveFailure.Error(),
+		})
+	case sig := <-signals:
+		utils.Info("Shutting down authentication service", map[string]interface{}{
+			"signal": sig.String(),
+		})
+
+		// Stop receiving new calls from load balancers before draining the ones in flight
+		healthServer.Shutdown()
+		stopGracefully(grpcServer, app.Config.ShutdownTimeout)
+	}
+
+	// Cancelling the workers aborts the queries of their current run; waiting for them to return ensures
+	// none of them uses the database once it is closed
+	stopWorkers()
+	workers.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.Config.ShutdownTimeout)
+	defer cancel()
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		utils.Warn("Failed to stop the metrics server", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	if err := sqlDB.Close(); err != nil {
+		return err
+	}
+	return serveFailure
+}
+
+// stopGracefully waits for the calls in flight to finish, and cancels the ones still running after timeout
+func stopGracefully(grpcServer *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		utils.Warn("Shutdown timeout reached, cancelling the remaining calls", map[string]interface{}{
+			"timeout": timeout.String(),
+		})
+		grpcServer.Stop()
+	}
+}
+
+// runHealthChecks sets the serving status of the services from their readiness every interval until ctx is cancelled.
+// Changes of status are logged.
+func runHealthChecks(ctx context.Context, healthService services.HealthService, healthServer *health.Server, serviceNames []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		err := healthService.CheckReadiness(checkCtx)
+		cancel()
+
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if status != previous {
+			if err != nil {
+				utils.Warn("Service not ready", map[string]interface{}{
+					"error": err.Error(),
+				})
+			} else {
+				utils.Info("Service ready", map[string]interface{}{})
+			}
+			previous = status
+		}
+
+		// Statuses set after the health server is shut down are ignored, so they stay NOT_SERVING
+		for _, name := range serviceNames {
+			healthServer.SetServingStatus(name, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newMetricsServer returns the server of the Prometheus metrics on /metrics
+func newMetricsServer(port string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return &http.Server{Addr: ":" + port, Handler: mux}
+}
+
+// runMetricsServer serves the metrics until the server is shut down
+func runMetricsServer(server *http.Server) {
+	utils.Info("Starting metrics server", map[string]interface{}{
+		"addr": server.Addr,
+	})
+	if err := server.ListenAndServe(); err != nil && !goerrors.Is(err, http.ErrServerClosed) {
+		utils.Error("Metrics server stopped", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// runCredentialExpiry processes credential expirations every interval until ctx is cancelled
+func runCredentialExpiry(ctx context.Context, credentialService services.CredentialService, interval, warningWindow time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := credentialService.ProcessExpirations(ctx, warningWindow); err != nil {
+			utils.Error("Failed to process credential expirations", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAccountErasure erases the accounts due for deletion every interval until ctx is cancelled
+func runAccountErasure(ctx context.Context, erasureService services.ErasureService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := erasureService.ProcessDueDeletions(ctx); err != nil {
+			utils.Error("Failed to process account deletions", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDataExports processes pending and expired data exports every interval until ctx is cancelled
+func runDataExports(ctx context.Context, exportService services.ExportService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := exportService.ProcessPendingExports(ctx); err != nil {
+			utils.Error("Failed to process data exports", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOutboxRelay publishes the events waiting in the outbox every interval until ctx is cancelled
+func runOutboxRelay(ctx context.Context, outboxService services.OutboxService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := outboxService.RelayEvents(ctx); err != nil {
+			utils.Error("Failed to relay outbox events", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runWebhookDeliveries sends the webhook deliveries that are due every interval until ctx is cancelled
+func runWebhookDeliveries(ctx context.Context, webhookService services.WebhookService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := webhookService.DeliverDue(ctx); err != nil {
+			utils.Error("Failed to send webhook deliveries", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
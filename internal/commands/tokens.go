@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/pkg/config"
+)
+
+var rotateKeysCmd = &Command{
+	Name:        "rotate-keys",
+	Description: "Generate a new token signing key and retire the current one",
+}
+
+var verifyTokenCmd = &Command{
+	Name:        "verify-token",
+	Usage:       "-token <jwt>",
+	Description: "Verify a token and print its claims",
+}
+
+func init() {
+	rotateKeysCmd.Run = runRotateKeys
+	verifyTokenCmd.Run = runVerifyToken
+
+	register(rotateKeysCmd)
+	register(verifyTokenCmd)
+}
+
+func runRotateKeys(cfg *config.Config, args []string) error {
+	if err := newFlagSet(rotateKeysCmd).Parse(args); err != nil {
+		return err
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	kid, err := app.AuthService.RotateSigningKey(context.Background(), "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("New signing key %s is active\n", kid)
+	return nil
+}
+
+func runVerifyToken(cfg *config.Config, args []string) error {
+	fs := newFlagSet(verifyTokenCmd)
+	token := fs.String("token", "", "token to verify")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"token": *token}); err != nil {
+		return err
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	claims, err := app.AuthService.VerifyToken(context.Background(), *token)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Token is valid")
+	fmt.Printf("user_id:     %s\n", claims.UserID)
+	fmt.Printf("role:        %s\n", claims.Role)
+	fmt.Printf("roles:       %s\n", strings.Join(claims.Roles, ", "))
+	fmt.Printf("permissions: %s\n", strings.Join(claims.Permissions, ", "))
+	return nil
+}
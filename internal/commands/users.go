@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/pkg/config"
+)
+
+var createAdminCmd = &Command{
+	Name:        "create-admin",
+	Usage:       "-username <name> -email <email> [-password-file <path>]",
+	Description: "Create an admin user. The password is read from -password-file, or prompted for on standard input",
+}
+
+var resetPasswordCmd = &Command{
+	Name:        "reset-password",
+	Usage:       "-user <id|email|username> [-password-file <path>]",
+	Description: "Set a new password for a user. The password is read from -password-file, or prompted for on standard input",
+}
+
+var disableUserCmd = &Command{
+	Name:        "disable-user",
+	Usage:       "-user <id|email|username>",
+	Description: "Disable a user so they can no longer log in",
+}
+
+var listUsersCmd = &Command{
+	Name:        "list-users",
+	Usage:       "[-role <role>] [-limit <n>] [-page-token <token>] [-sort-by <column>] [-sort-order asc|desc]",
+	Description: "List users",
+}
+
+func init() {
+	createAdminCmd.Run = runCreateAdmin
+	resetPasswordCmd.Run = runResetPassword
+	disableUserCmd.Run = runDisableUser
+	listUsersCmd.Run = runListUsers
+
+	register(createAdminCmd)
+	register(resetPasswordCmd)
+	register(disableUserCmd)
+	register(listUsersCmd)
+}
+
+func runCreateAdmin(cfg *config.Config, args []string) error {
+	fs := newFlagSet(createAdminCmd)
+	username := fs.String("username", "", "username of the new admin")
+	email := fs.String("email", "", "email of the new admin")
+	passwordFile := fs.String("password-file", "", "file holding the password of the new admin, - for standard input")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"username": *username, "email": *email}); err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile, "Password: ")
+	if err != nil {
+		return err
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	userID, err := app.AuthService.CreateUser(context.Background(), "", *username, *email, password, "admin")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created admin %s (%s)\n", *username, userID)
+	return nil
+}
+
+func runResetPassword(cfg *config.Config, args []string) error {
+	fs := newFlagSet(resetPasswordCmd)
+	identifier := fs.String("user", "", "ID, email or username of the user")
+	passwordFile := fs.String("password-file", "", "file holding the new password, - for standard input")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"user": *identifier}); err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile, "New password: ")
+	if err != nil {
+		return err
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	user, err := app.AuthService.FindUser(context.Background(), *identifier)
+	if err != nil {
+		return err
+	}
+
+	if err := app.AuthService.ResetPassword(context.Background(), "", user.ID.String(), password); err != nil {
+		return err
+	}
+
+	fmt.Printf("Password reset for %s (%s)\n", user.Username, user.ID)
+	return nil
+}
+
+func runDisableUser(cfg *config.Config, args []string) error {
+	fs := newFlagSet(disableUserCmd)
+	identifier := fs.String("user", "", "ID, email or username of the user")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"user": *identifier}); err != nil {
+		return err
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	user, err := app.AuthService.FindUser(context.Background(), *identifier)
+	if err != nil {
+		return err
+	}
+
+	if err := app.AuthService.DisableUser(context.Background(), "", user.ID.String()); err != nil {
+		return err
+	}
+
+	fmt.Printf("Disabled %s (%s)\n", user.Username, user.ID)
+	return nil
+}
+
+func runListUsers(cfg *config.Config, args []string) error {
+	fs := newFlagSet(listUsersCmd)
+	role := fs.String("role", "", "only list users with this role")
+	limit := fs.Int("limit", 50, "maximum number of users to list")
+	pageToken := fs.String("page-token", "", "token of the page to list, as printed by the previous call")
+	sortBy := fs.String("sort-by", "created_at", "column to sort by: created_at, username or email")
+	sortOrder := fs.String("sort-order", "asc", "sort order: asc or desc")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	var filters []services.Filter
+	if *role != "" {
+		filters = append(filters, services.Filter{Column: "role", Operator: "=", Value: *role})
+	}
+
+	users, nextPageToken, err := app.AdminService.ListUsers(context.Background(), filters, *limit, *pageToken, *sortBy, *sortOrder)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tROLES\tSTATUS\tCREATED")
+	for _, user := range users {
+		status := "active"
+		if user.DisabledAt != nil {
+			status = "disabled"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", user.ID, user.Username, user.Email, strings.Join(user.Roles, ","), status, user.CreatedAt.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if nextPageToken != "" {
+		fmt.Printf("\nNext page: -page-token %s\n", nextPageToken)
+	}
+	return nil
+}
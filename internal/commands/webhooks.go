@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/webhook"
+	"github.com/PharmaKart/authentication-svc/pkg/config"
+)
+
+var webhookReceiverCmd = &Command{
+	Name:        "webhook-receiver",
+	Usage:       "-secret <secret> [-addr 127.0.0.1:8089] [-status 204]",
+	Description: "Run a local stand-in for a partner endpoint that verifies and prints webhook deliveries",
+}
+
+func init() {
+	webhookReceiverCmd.Run = runWebhookReceiver
+
+	register(webhookReceiverCmd)
+}
+
+func runWebhookReceiver(cfg *config.Config, args []string) error {
+	fs := newFlagSet(webhookReceiverCmd)
+	secret := fs.String("secret", "", "signing secret returned when the subscription was created")
+	addr := fs.String("addr", "127.0.0.1:8089", "address to listen on")
+	status := fs.Int("status", http.StatusNoContent, "status to answer valid deliveries with, e.g. 500 to exercise retries")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"secret": *secret}); err != nil {
+		return err
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = webhook.Verify(*secret, r.Header.Get(webhook.HeaderSignature), r.Header.Get(webhook.HeaderTimestamp), body, webhook.DefaultTolerance, time.Now())
+		if err != nil {
+			fmt.Printf("Rejected delivery %s: %v\n", r.Header.Get(webhook.HeaderDeliveryID), err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Printf("Delivery %s of %s event %s answered with %d\n%s\n",
+			r.Header.Get(webhook.HeaderDeliveryID),
+			r.Header.Get(webhook.HeaderEventType),
+			r.Header.Get(webhook.HeaderEventID),
+			*status,
+			body,
+		)
+		w.WriteHeader(*status)
+	})
+
+	fmt.Printf("Listening for webhook deliveries on http://%s\n", *addr)
+	return http.ListenAndServe(*addr, handler)
+}
@@ -0,0 +1,145 @@
+package eligibility
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+)
+
+// anyProvince is the key of the settings that apply to provinces without their own entry
+const anyProvince = "*"
+
+// Rules holds the age thresholds published in tokens and the age restrictions of product rules
+type Rules struct {
+	// AgeThresholds are the ages reported in the age_over claim, keyed by province code or "*"
+	AgeThresholds map[string][]int `json:"age_thresholds"`
+	Rules         map[string]Rule  `json:"rules"`
+}
+
+// Rule is an age restriction, e.g. for pseudoephedrine or nicotine replacement products
+type Rule struct {
+	Description string `json:"description"`
+	// MinAge is keyed by province code or "*"
+	MinAge map[string]int `json:"min_age"`
+}
+
+// Default returns the rules used when no eligibility file is configured
+func Default() *Rules {
+	return &Rules{
+		AgeThresholds: map[string][]int{anyProvince: {16, 18, 19}},
+		Rules: map[string]Rule{
+			"pseudoephedrine": {
+				Description: "Products containing pseudoephedrine",
+				MinAge:      map[string]int{anyProvince: 18},
+			},
+			"nicotine_replacement": {
+				Description: "Nicotine replacement therapy",
+				MinAge:      map[string]int{anyProvince: 18},
+			},
+		},
+	}
+}
+
+// Load reads the rules from path. A missing file yields the default rules.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		utils.Warn("Eligibility file not found, using default age rules", map[string]interface{}{
+			"path": path,
+		})
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	utils.Info("Eligibility rules loaded", map[string]interface{}{
+		"path":  path,
+		"rules": len(rules.Rules),
+	})
+	return &rules, nil
+}
+
+// Validate checks that every age is positive and every rule applies somewhere
+func (r *Rules) Validate() error {
+	for province, thresholds := range r.AgeThresholds {
+		for _, age := range thresholds {
+			if age <= 0 {
+				return fmt.Errorf("age threshold %d for %q must be positive", age, province)
+			}
+		}
+	}
+	for name, rule := range r.Rules {
+		if len(rule.MinAge) == 0 {
+			return fmt.Errorf("rule %q has no minimum age", name)
+		}
+		for province, age := range rule.MinAge {
+			if age <= 0 {
+				return fmt.Errorf("minimum age %d of rule %q for %q must be positive", age, name, province)
+			}
+		}
+	}
+	return nil
+}
+
+// AgeOver returns the thresholds of the province that the age reaches, in ascending order
+func (r *Rules) AgeOver(age int, province string) []int {
+	thresholds, ok := r.AgeThresholds[province]
+	if !ok {
+		thresholds = r.AgeThresholds[anyProvince]
+	}
+
+	result := []int{}
+	for _, threshold := range thresholds {
+		if age >= threshold {
+			result = append(result, threshold)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// MinAge returns the minimum age the rule requires in the province. ok is false for unknown rules
+// and for provinces the rule has no age for.
+func (r *Rules) MinAge(rule, province string) (age int, ok bool) {
+	ruleDef, ok := r.Rules[rule]
+	if !ok {
+		return 0, false
+	}
+
+	if age, ok := ruleDef.MinAge[province]; ok {
+		return age, true
+	}
+	age, ok = ruleDef.MinAge[anyProvince]
+	return age, ok
+}
+
+// HasRule reports whether the rule is defined
+func (r *Rules) HasRule(rule string) bool {
+	_, ok := r.Rules[rule]
+	return ok
+}
+
+// Age returns the age in whole years on the given day of someone born on dob
+func Age(dob, now time.Time) int {
+	dob = dob.UTC()
+	now = now.UTC()
+
+	age := now.Year() - dob.Year()
+	if now.Month() < dob.Month() || (now.Month() == dob.Month() && now.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
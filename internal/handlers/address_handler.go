@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/interceptors"
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+)
+
+type AddressHandler interface {
+	ListAddresses(ctx context.Context, req *proto.ListAddressesRequest) (*proto.ListAddressesResponse, error)
+	CreateAddress(ctx context.Context, req *proto.CreateAddressRequest) (*proto.CreateAddressResponse, error)
+	UpdateAddress(ctx context.Context, req *proto.UpdateAddressRequest) (*proto.UpdateAddressResponse, error)
+	DeleteAddress(ctx context.Context, req *proto.DeleteAddressRequest) (*proto.DeleteAddressResponse, error)
+}
+
+type addressHandler struct {
+	proto.UnimplementedAddressServiceServer
+	addressService services.AddressService
+}
+
+func NewAddressHandler(addressService services.AddressService) *addressHandler {
+	return &addressHandler{
+		addressService: addressService,
+	}
+}
+
+func (h *addressHandler) ListAddresses(ctx context.Context, req *proto.ListAddressesRequest) (*proto.ListAddressesResponse, error) {
+	if !interceptors.HasPermission(ctx, "profile:read") {
+		message, protoErr := toProtoError(ctx, errors.NewAuthError("auth.insufficient_permissions"))
+		return &proto.ListAddressesResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	addresses, err := h.addressService.ListAddresses(ctx, interceptors.UserIDFromContext(ctx))
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListAddressesResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoAddresses := make([]*proto.Address, 0, len(addresses))
+	for i := range addresses {
+		protoAddresses = append(protoAddresses, toProtoAddress(&addresses[i]))
+	}
+
+	return &proto.ListAddressesResponse{Success: true, Message: "Addresses retrieved successfully", Addresses: protoAddresses}, nil
+}
+
+func (h *addressHandler) CreateAddress(ctx context.Context, req *proto.CreateAddressRequest) (*proto.CreateAddressResponse, error) {
+	if !interceptors.HasPermission(ctx, "profile:update") {
+		message, protoErr := toProtoError(ctx, errors.NewAuthError("auth.insufficient_permissions"))
+		return &proto.CreateAddressResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	address, err := h.addressService.CreateAddress(ctx, interceptors.UserIDFromContext(ctx), toAddressInput(req.GetAddress()))
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CreateAddressResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CreateAddressResponse{Success: true, Message: "Address created successfully", Address: toProtoAddress(address)}, nil
+}
+
+func (h *addressHandler) UpdateAddress(ctx context.Context, req *proto.UpdateAddressRequest) (*proto.UpdateAddressResponse, error) {
+	if !interceptors.HasPermission(ctx, "profile:update") {
+		message, protoErr := toProtoError(ctx, errors.NewAuthError("auth.insufficient_permissions"))
+		return &proto.UpdateAddressResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	address, err := h.addressService.UpdateAddress(ctx, interceptors.UserIDFromContext(ctx), req.GetAddress().GetId(), toAddressInput(req.GetAddress()))
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.UpdateAddressResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.UpdateAddressResponse{Success: true, Message: "Address updated successfully", Address: toProtoAddress(address)}, nil
+}
+
+func (h *addressHandler) DeleteAddress(ctx context.Context, req *proto.DeleteAddressRequest) (*proto.DeleteAddressResponse, error) {
+	if !interceptors.HasPermission(ctx, "profile:update") {
+		message, protoErr := toProtoError(ctx, errors.NewAuthError("auth.insufficient_permissions"))
+		return &proto.DeleteAddressResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	if err := h.addressService.DeleteAddress(ctx, interceptors.UserIDFromContext(ctx), req.AddressId); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DeleteAddressResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.DeleteAddressResponse{Success: true, Message: "Address deleted successfully"}, nil
+}
+
+func toAddressInput(address *proto.Address) services.AddressInput {
+	return services.AddressInput{
+		Label:           address.GetLabel(),
+		StreetLine1:     address.GetStreetLine1(),
+		StreetLine2:     address.GetStreetLine2(),
+		City:            address.GetCity(),
+		Province:        address.GetProvince(),
+		PostalCode:      address.GetPostalCode(),
+		Country:         address.GetCountry(),
+		DefaultShipping: address.GetDefaultShipping(),
+		DefaultBilling:  address.GetDefaultBilling(),
+	}
+}
+
+func toProtoAddress(address *models.Address) *proto.Address {
+	protoAddress := &proto.Address{
+		Id:              address.ID.String(),
+		Label:           address.Label,
+		StreetLine1:     address.StreetLine1,
+		City:            address.City,
+		Province:        address.Province,
+		PostalCode:      address.PostalCode,
+		Country:         address.Country,
+		DefaultShipping: address.IsDefaultShipping,
+		DefaultBilling:  address.IsDefaultBilling,
+		CreatedAt:       address.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       address.UpdatedAt.Format(time.RFC3339),
+	}
+	if address.StreetLine2 != nil {
+		protoAddress.StreetLine2 = *address.StreetLine2
+	}
+	return protoAddress
+}
@@ -0,0 +1,530 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/interceptors"
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+)
+
+type AdminHandler interface {
+	CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.CreateUserResponse, error)
+	GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.GetUserResponse, error)
+	ListUsers(ctx context.Context, req *proto.ListUsersRequest) (*proto.ListUsersResponse, error)
+	UpdateUser(ctx context.Context, req *proto.UpdateUserRequest) (*proto.UpdateUserResponse, error)
+	DisableUser(ctx context.Context, req *proto.DisableUserRequest) (*proto.DisableUserResponse, error)
+	EnableUser(ctx context.Context, req *proto.EnableUserRequest) (*proto.EnableUserResponse, error)
+	DeleteUser(ctx context.Context, req *proto.DeleteUserRequest) (*proto.DeleteUserResponse, error)
+	ListRoles(ctx context.Context, req *proto.ListRolesRequest) (*proto.ListRolesResponse, error)
+	CreateRole(ctx context.Context, req *proto.CreateRoleRequest) (*proto.CreateRoleResponse, error)
+	UpdateRole(ctx context.Context, req *proto.UpdateRoleRequest) (*proto.UpdateRoleResponse, error)
+	DeleteRole(ctx context.Context, req *proto.DeleteRoleRequest) (*proto.DeleteRoleResponse, error)
+	ListPermissions(ctx context.Context, req *proto.ListPermissionsRequest) (*proto.ListPermissionsResponse, error)
+	AssignRole(ctx context.Context, req *proto.AssignRoleRequest) (*proto.AssignRoleResponse, error)
+	RevokeRole(ctx context.Context, req *proto.RevokeRoleRequest) (*proto.RevokeRoleResponse, error)
+	CreateInvitation(ctx context.Context, req *proto.CreateInvitationRequest) (*proto.CreateInvitationResponse, error)
+	ListInvitations(ctx context.Context, req *proto.ListInvitationsRequest) (*proto.ListInvitationsResponse, error)
+	ResendInvitation(ctx context.Context, req *proto.ResendInvitationRequest) (*proto.ResendInvitationResponse, error)
+	RevokeInvitation(ctx context.Context, req *proto.RevokeInvitationRequest) (*proto.RevokeInvitationResponse, error)
+	ListCredentials(ctx context.Context, req *proto.ListCredentialsRequest) (*proto.ListCredentialsResponse, error)
+	ApproveCredential(ctx context.Context, req *proto.ApproveCredentialRequest) (*proto.ApproveCredentialResponse, error)
+	RejectCredential(ctx context.Context, req *proto.RejectCredentialRequest) (*proto.RejectCredentialResponse, error)
+	PublishPolicy(ctx context.Context, req *proto.PublishPolicyRequest) (*proto.PublishPolicyResponse, error)
+	QueryAuditEvents(ctx context.Context, req *proto.QueryAuditEventsRequest) (*proto.QueryAuditEventsResponse, error)
+	CreateWebhookSubscription(ctx context.Context, req *proto.CreateWebhookSubscriptionRequest) (*proto.CreateWebhookSubscriptionResponse, error)
+	ListWebhookSubscriptions(ctx context.Context, req *proto.ListWebhookSubscriptionsRequest) (*proto.ListWebhookSubscriptionsResponse, error)
+	UpdateWebhookSubscription(ctx context.Context, req *proto.UpdateWebhookSubscriptionRequest) (*proto.UpdateWebhookSubscriptionResponse, error)
+	DeleteWebhookSubscription(ctx context.Context, req *proto.DeleteWebhookSubscriptionRequest) (*proto.DeleteWebhookSubscriptionResponse, error)
+	ListWebhookDeadLetters(ctx context.Context, req *proto.ListWebhookDeadLettersRequest) (*proto.ListWebhookDeadLettersResponse, error)
+	ReplayWebhookDeadLetter(ctx context.Context, req *proto.ReplayWebhookDeadLetterRequest) (*proto.ReplayWebhookDeadLetterResponse, error)
+}
+
+type adminHandler struct {
+	proto.UnimplementedAdminServiceServer
+	adminService      services.AdminService
+	roleService       services.RoleService
+	invitationService services.InvitationService
+	credentialService services.CredentialService
+	consentService    services.ConsentService
+	auditService      services.AuditService
+	webhookService    services.WebhookService
+}
+
+func NewAdminHandler(adminService services.AdminService, roleService services.RoleService, invitationService services.InvitationService, credentialService services.CredentialService, consentService services.ConsentService, auditService services.AuditService, webhookService services.WebhookService) *adminHandler {
+	return &adminHandler{
+		adminService:      adminService,
+		roleService:       roleService,
+		invitationService: invitationService,
+		credentialService: credentialService,
+		consentService:    consentService,
+		auditService:      auditService,
+		webhookService:    webhookService,
+	}
+}
+
+func (h *adminHandler) CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.CreateUserResponse, error) {
+	userID, err := h.adminService.CreateUser(ctx, interceptors.UserIDFromContext(ctx), req.Username, req.Email, req.Password, req.Role)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CreateUserResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CreateUserResponse{Success: true, Message: "User created successfully", UserId: userID}, nil
+}
+
+func (h *adminHandler) GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.GetUserResponse, error) {
+	user, err := h.adminService.GetUser(ctx, req.UserId)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetUserResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.GetUserResponse{Success: true, Message: "User retrieved successfully", User: toProtoUser(user)}, nil
+}
+
+func (h *adminHandler) ListUsers(ctx context.Context, req *proto.ListUsersRequest) (*proto.ListUsersResponse, error) {
+	filters := make([]services.Filter, 0, len(req.Filters))
+	for _, filter := range req.Filters {
+		filters = append(filters, services.Filter{
+			Column:   filter.Column,
+			Operator: filter.Operator,
+			Value:    filter.Value,
+		})
+	}
+
+	users, nextPageToken, err := h.adminService.ListUsers(ctx, filters, int(req.PageSize), req.PageToken, req.SortBy, req.SortOrder)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListUsersResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoUsers := make([]*proto.User, 0, len(users))
+	for i := range users {
+		protoUsers = append(protoUsers, toProtoUser(&users[i]))
+	}
+
+	return &proto.ListUsersResponse{Success: true, Message: "Users retrieved successfully", Users: protoUsers, NextPageToken: nextPageToken}, nil
+}
+
+func (h *adminHandler) UpdateUser(ctx context.Context, req *proto.UpdateUserRequest) (*proto.UpdateUserResponse, error) {
+	user, err := h.adminService.UpdateUser(ctx, interceptors.UserIDFromContext(ctx), req.UserId, req.Username, req.Email, req.Role)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.UpdateUserResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.UpdateUserResponse{Success: true, Message: "User updated successfully", User: toProtoUser(user)}, nil
+}
+
+func (h *adminHandler) DisableUser(ctx context.Context, req *proto.DisableUserRequest) (*proto.DisableUserResponse, error) {
+	if err := h.adminService.DisableUser(ctx, interceptors.UserIDFromContext(ctx), req.UserId); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DisableUserResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.DisableUserResponse{Success: true, Message: "User disabled successfully"}, nil
+}
+
+func (h *adminHandler) EnableUser(ctx context.Context, req *proto.EnableUserRequest) (*proto.EnableUserResponse, error) {
+	if err := h.adminService.EnableUser(ctx, interceptors.UserIDFromContext(ctx), req.UserId); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.EnableUserResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.EnableUserResponse{Success: true, Message: "User enabled successfully"}, nil
+}
+
+func (h *adminHandler) DeleteUser(ctx context.Context, req *proto.DeleteUserRequest) (*proto.DeleteUserResponse, error) {
+	if err := h.adminService.DeleteUser(ctx, interceptors.UserIDFromContext(ctx), req.UserId); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DeleteUserResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.DeleteUserResponse{Success: true, Message: "User deleted successfully"}, nil
+}
+
+func (h *adminHandler) ListRoles(ctx context.Context, req *proto.ListRolesRequest) (*proto.ListRolesResponse, error) {
+	roles, err := h.roleService.ListRoles(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListRolesResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoRoles := make([]*proto.Role, 0, len(roles))
+	for i := range roles {
+		protoRoles = append(protoRoles, toProtoRole(&roles[i]))
+	}
+
+	return &proto.ListRolesResponse{Success: true, Message: "Roles retrieved successfully", Roles: protoRoles}, nil
+}
+
+func (h *adminHandler) CreateRole(ctx context.Context, req *proto.CreateRoleRequest) (*proto.CreateRoleResponse, error) {
+	role, err := h.roleService.CreateRole(ctx, interceptors.UserIDFromContext(ctx), req.Name, req.Description, req.Permissions)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CreateRoleResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CreateRoleResponse{Success: true, Message: "Role created successfully", Role: toProtoRole(role)}, nil
+}
+
+func (h *adminHandler) UpdateRole(ctx context.Context, req *proto.UpdateRoleRequest) (*proto.UpdateRoleResponse, error) {
+	role, err := h.roleService.UpdateRole(ctx, interceptors.UserIDFromContext(ctx), req.Name, req.Description, req.Permissions, req.ReplacePermissions)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.UpdateRoleResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.UpdateRoleResponse{Success: true, Message: "Role updated successfully", Role: toProtoRole(role)}, nil
+}
+
+func (h *adminHandler) DeleteRole(ctx context.Context, req *proto.DeleteRoleRequest) (*proto.DeleteRoleResponse, error) {
+	if err := h.roleService.DeleteRole(ctx, interceptors.UserIDFromContext(ctx), req.Name); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DeleteRoleResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.DeleteRoleResponse{Success: true, Message: "Role deleted successfully"}, nil
+}
+
+func (h *adminHandler) ListPermissions(ctx context.Context, req *proto.ListPermissionsRequest) (*proto.ListPermissionsResponse, error) {
+	permissions, err := h.roleService.ListPermissions(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListPermissionsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoPermissions := make([]*proto.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		protoPermissions = append(protoPermissions, &proto.Permission{Name: permission.Name, Description: permission.Description})
+	}
+
+	return &proto.ListPermissionsResponse{Success: true, Message: "Permissions retrieved successfully", Permissions: protoPermissions}, nil
+}
+
+func (h *adminHandler) AssignRole(ctx context.Context, req *proto.AssignRoleRequest) (*proto.AssignRoleResponse, error) {
+	if err := h.roleService.AssignRole(ctx, interceptors.UserIDFromContext(ctx), req.UserId, req.Role); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.AssignRoleResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.AssignRoleResponse{Success: true, Message: "Role assigned successfully"}, nil
+}
+
+func (h *adminHandler) RevokeRole(ctx context.Context, req *proto.RevokeRoleRequest) (*proto.RevokeRoleResponse, error) {
+	if err := h.roleService.RevokeRole(ctx, interceptors.UserIDFromContext(ctx), req.UserId, req.Role); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RevokeRoleResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.RevokeRoleResponse{Success: true, Message: "Role revoked successfully"}, nil
+}
+
+func (h *adminHandler) CreateInvitation(ctx context.Context, req *proto.CreateInvitationRequest) (*proto.CreateInvitationResponse, error) {
+	invitation, code, err := h.invitationService.CreateInvitation(ctx, interceptors.UserIDFromContext(ctx), req.Email, req.Role, req.StoreId)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CreateInvitationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CreateInvitationResponse{Success: true, Message: "Invitation created successfully", Invitation: toProtoInvitation(invitation), Code: code}, nil
+}
+
+func (h *adminHandler) ListInvitations(ctx context.Context, req *proto.ListInvitationsRequest) (*proto.ListInvitationsResponse, error) {
+	invitations, nextPageToken, err := h.invitationService.ListInvitations(ctx, req.Status, int(req.PageSize), req.PageToken)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListInvitationsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoInvitations := make([]*proto.Invitation, 0, len(invitations))
+	for i := range invitations {
+		protoInvitations = append(protoInvitations, toProtoInvitation(&invitations[i]))
+	}
+
+	return &proto.ListInvitationsResponse{Success: true, Message: "Invitations retrieved successfully", Invitations: protoInvitations, NextPageToken: nextPageToken}, nil
+}
+
+func (h *adminHandler) ResendInvitation(ctx context.Context, req *proto.ResendInvitationRequest) (*proto.ResendInvitationResponse, error) {
+	invitation, code, err := h.invitationService.ResendInvitation(ctx, req.InvitationId)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ResendInvitationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.ResendInvitationResponse{Success: true, Message: "Invitation resent successfully", Invitation: toProtoInvitation(invitation), Code: code}, nil
+}
+
+func (h *adminHandler) RevokeInvitation(ctx context.Context, req *proto.RevokeInvitationRequest) (*proto.RevokeInvitationResponse, error) {
+	if err := h.invitationService.RevokeInvitation(ctx, req.InvitationId); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RevokeInvitationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.RevokeInvitationResponse{Success: true, Message: "Invitation revoked successfully"}, nil
+}
+
+func (h *adminHandler) ListCredentials(ctx context.Context, req *proto.ListCredentialsRequest) (*proto.ListCredentialsResponse, error) {
+	credentials, nextPageToken, err := h.credentialService.ListCredentials(ctx, req.Status, int(req.PageSize), req.PageToken)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListCredentialsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoCredentials := make([]*proto.Credential, 0, len(credentials))
+	for i := range credentials {
+		protoCredentials = append(protoCredentials, toProtoCredential(&credentials[i]))
+	}
+
+	return &proto.ListCredentialsResponse{Success: true, Message: "Credentials retrieved successfully", Credentials: protoCredentials, NextPageToken: nextPageToken}, nil
+}
+
+func (h *adminHandler) ApproveCredential(ctx context.Context, req *proto.ApproveCredentialRequest) (*proto.ApproveCredentialResponse, error) {
+	credential, err := h.credentialService.ApproveCredential(ctx, interceptors.UserIDFromContext(ctx), req.CredentialId)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ApproveCredentialResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.ApproveCredentialResponse{Success: true, Message: "Credential approved successfully", Credential: toProtoCredential(credential)}, nil
+}
+
+func (h *adminHandler) RejectCredential(ctx context.Context, req *proto.RejectCredentialRequest) (*proto.RejectCredentialResponse, error) {
+	credential, err := h.credentialService.RejectCredential(ctx, interceptors.UserIDFromContext(ctx), req.CredentialId, req.Reason)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RejectCredentialResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.RejectCredentialResponse{Success: true, Message: "Credential rejected successfully", Credential: toProtoCredential(credential)}, nil
+}
+
+func (h *adminHandler) PublishPolicy(ctx context.Context, req *proto.PublishPolicyRequest) (*proto.PublishPolicyResponse, error) {
+	policy, err := h.consentService.PublishPolicy(ctx, req.Policy, req.Version, req.Title, req.Url, req.Mandatory, req.EffectiveAt)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.PublishPolicyResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.PublishPolicyResponse{Success: true, Message: "Policy published successfully", Policy: toProtoPolicy(policy)}, nil
+}
+
+func (h *adminHandler) QueryAuditEvents(ctx context.Context, req *proto.QueryAuditEventsRequest) (*proto.QueryAuditEventsResponse, error) {
+	filter := services.AuditFilter{
+		Action:    req.Action,
+		ActorID:   req.ActorId,
+		SubjectID: req.SubjectId,
+		From:      req.From,
+		To:        req.To,
+	}
+	events, nextPageToken, err := h.auditService.QueryEvents(ctx, filter, int(req.PageSize), req.PageToken)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.QueryAuditEventsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoEvents := make([]*proto.AuditEvent, 0, len(events))
+	for i := range events {
+		protoEvents = append(protoEvents, toProtoAuditEvent(&events[i]))
+	}
+
+	return &proto.QueryAuditEventsResponse{Success: true, Message: "Audit events retrieved successfully", Events: protoEvents, NextPageToken: nextPageToken}, nil
+}
+
+func (h *adminHandler) CreateWebhookSubscription(ctx context.Context, req *proto.CreateWebhookSubscriptionRequest) (*proto.CreateWebhookSubscriptionResponse, error) {
+	subscription, err := h.webhookService.CreateSubscription(ctx, interceptors.UserIDFromContext(ctx), req.Url, req.Description, req.EventTypes)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CreateWebhookSubscriptionResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CreateWebhookSubscriptionResponse{Success: true, Message: "Webhook subscription created successfully", Subscription: toProtoWebhookSubscription(subscription), Secret: subscription.Secret}, nil
+}
+
+func (h *adminHandler) ListWebhookSubscriptions(ctx context.Context, req *proto.ListWebhookSubscriptionsRequest) (*proto.ListWebhookSubscriptionsResponse, error) {
+	subscriptions, err := h.webhookService.ListSubscriptions(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListWebhookSubscriptionsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoSubscriptions := make([]*proto.WebhookSubscription, 0, len(subscriptions))
+	for i := range subscriptions {
+		protoSubscriptions = append(protoSubscriptions, toProtoWebhookSubscription(&subscriptions[i]))
+	}
+
+	return &proto.ListWebhookSubscriptionsResponse{Success: true, Message: "Webhook subscriptions retrieved successfully", Subscriptions: protoSubscriptions}, nil
+}
+
+func (h *adminHandler) UpdateWebhookSubscription(ctx context.Context, req *proto.UpdateWebhookSubscriptionRequest) (*proto.UpdateWebhookSubscriptionResponse, error) {
+	subscription, err := h.webhookService.UpdateSubscription(ctx, req.SubscriptionId, req.Url, req.Description, req.EventTypes, req.AllEvents, req.Active)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.UpdateWebhookSubscriptionResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.UpdateWebhookSubscriptionResponse{Success: true, Message: "Webhook subscription updated successfully", Subscription: toProtoWebhookSubscription(subscription)}, nil
+}
+
+func (h *adminHandler) DeleteWebhookSubscription(ctx context.Context, req *proto.DeleteWebhookSubscriptionRequest) (*proto.DeleteWebhookSubscriptionResponse, error) {
+	if err := h.webhookService.DeleteSubscription(ctx, req.SubscriptionId); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DeleteWebhookSubscriptionResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.DeleteWebhookSubscriptionResponse{Success: true, Message: "Webhook subscription deleted successfully"}, nil
+}
+
+func (h *adminHandler) ListWebhookDeadLetters(ctx context.Context, req *proto.ListWebhookDeadLettersRequest) (*proto.ListWebhookDeadLettersResponse, error) {
+	deadLetters, nextPageToken, err := h.webhookService.ListDeadLetters(ctx, req.SubscriptionId, int(req.PageSize), req.PageToken)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListWebhookDeadLettersResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoDeadLetters := make([]*proto.WebhookDeadLetter, 0, len(deadLetters))
+	for i := range deadLetters {
+		protoDeadLetters = append(protoDeadLetters, toProtoWebhookDeadLetter(&deadLetters[i]))
+	}
+
+	return &proto.ListWebhookDeadLettersResponse{Success: true, Message: "Dead letters retrieved successfully", DeadLetters: protoDeadLetters, NextPageToken: nextPageToken}, nil
+}
+
+func (h *adminHandler) ReplayWebhookDeadLetter(ctx context.Context, req *proto.ReplayWebhookDeadLetterRequest) (*proto.ReplayWebhookDeadLetterResponse, error) {
+	delivery, err := h.webhookService.ReplayDeadLetter(ctx, req.DeadLetterId)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ReplayWebhookDeadLetterResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.ReplayWebhookDeadLetterResponse{Success: true, Message: "Dead letter queued for delivery", DeliveryId: delivery.ID.String()}, nil
+}
+
+func toProtoUser(user *services.UserDetails) *proto.User {
+	protoUser := &proto.User{
+		Id:        user.ID.String(),
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+		Roles:     user.Roles,
+	}
+	if user.StoreID != nil {
+		protoUser.StoreId = *user.StoreID
+	}
+	if user.DisabledAt != nil {
+		protoUser.Disabled = true
+		protoUser.DisabledAt = user.DisabledAt.Format(time.RFC3339)
+	}
+	return protoUser
+}
+
+func toProtoRole(role *models.Role) *proto.Role {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+
+	return &proto.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		System:      role.System,
+		Permissions: permissions,
+	}
+}
+
+func toProtoInvitation(invitation *models.Invitation) *proto.Invitation {
+	protoInvitation := &proto.Invitation{
+		Id:        invitation.ID.String(),
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		Status:    invitation.Status(time.Now()),
+		InvitedBy: invitation.InvitedBy.String(),
+		ExpiresAt: invitation.ExpiresAt.Format(time.RFC3339),
+		SentAt:    invitation.SentAt.Format(time.RFC3339),
+		CreatedAt: invitation.CreatedAt.Format(time.RFC3339),
+	}
+	if invitation.StoreID != nil {
+		protoInvitation.StoreId = *invitation.StoreID
+	}
+	if invitation.AcceptedAt != nil {
+		protoInvitation.AcceptedAt = invitation.AcceptedAt.Format(time.RFC3339)
+	}
+	if invitation.AcceptedUserID != nil {
+		protoInvitation.AcceptedUserId = invitation.AcceptedUserID.String()
+	}
+	if invitation.RevokedAt != nil {
+		protoInvitation.RevokedAt = invitation.RevokedAt.Format(time.RFC3339)
+	}
+	return protoInvitation
+}
+
+func toProtoAuditEvent(event *models.AuditEvent) *proto.AuditEvent {
+	protoEvent := &proto.AuditEvent{
+		Id:        event.ID.String(),
+		Sequence:  event.Sequence,
+		Action:    event.Action,
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if event.ActorID != nil {
+		protoEvent.ActorId = event.ActorID.String()
+	}
+	if event.SubjectID != nil {
+		protoEvent.SubjectId = event.SubjectID.String()
+	}
+
+	// Details are flattened to strings, nested values stay JSON encoded
+	var details map[string]interface{}
+	if err := json.Unmarshal([]byte(event.Details), &details); err == nil {
+		values := make(map[string]string, len(details))
+		for key, value := range details {
+			if text, ok := value.(string); ok {
+				values[key] = text
+				continue
+			}
+			encoded, _ := json.Marshal(value)
+			values[key] = string(encoded)
+		}
+		protoEvent.Details = utils.ConvertMapToKeyValuePairs(values)
+	}
+	return protoEvent
+}
+
+func toProtoWebhookSubscription(subscription *models.WebhookSubscription) *proto.WebhookSubscription {
+	return &proto.WebhookSubscription{
+		Id:          subscription.ID.String(),
+		Url:         subscription.URL,
+		Description: subscription.Description,
+		EventTypes:  subscription.EventTypeList(),
+		Active:      subscription.Active,
+		CreatedBy:   subscription.CreatedBy.String(),
+		CreatedAt:   subscription.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   subscription.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toProtoWebhookDeadLetter(deadLetter *models.WebhookDeadLetter) *proto.WebhookDeadLetter {
+	protoDeadLetter := &proto.WebhookDeadLetter{
+		Id:             deadLetter.ID.String(),
+		SubscriptionId: deadLetter.SubscriptionID.String(),
+		EventId:        deadLetter.EventID.String(),
+		EventType:      deadLetter.EventType,
+		Attempts:       int32(deadLetter.Attempts),
+		LastStatus:     int32(deadLetter.LastStatus),
+		CreatedAt:      deadLetter.CreatedAt.Format(time.RFC3339),
+	}
+	if deadLetter.LastError != nil {
+		protoDeadLetter.LastError = *deadLetter.LastError
+	}
+	if deadLetter.ReplayedAt != nil {
+		protoDeadLetter.ReplayedAt = deadLetter.ReplayedAt.Format(time.RFC3339)
+	}
+	return protoDeadLetter
+}
@@ -2,33 +2,50 @@ package handlers
 
 import (
 	"context"
+	"time"
 
 	"github.com/PharmaKart/authentication-svc/internal/proto"
-	"github.com/PharmaKart/authentication-svc/internal/repositories"
 	"github.com/PharmaKart/authentication-svc/internal/services"
-	"github.com/PharmaKart/authentication-svc/pkg/errors"
-	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/PharmaKart/authentication-svc/internal/tracing"
 )
 
 type AuthHandler interface {
 	Register(ctx context.Context, req *proto.RegisterRequest) (*proto.RegisterResponse, error)
 	Login(ctx context.Context, req *proto.LoginRequest) (*proto.LoginResponse, error)
 	VerifyToken(ctx context.Context, req *proto.VerifyTokenRequest) (*proto.VerifyTokenResponse, error)
+	CheckPermission(ctx context.Context, req *proto.CheckPermissionRequest) (*proto.CheckPermissionResponse, error)
+	Authorize(ctx context.Context, req *proto.AuthorizeRequest) (*proto.AuthorizeResponse, error)
+	AcceptInvitation(ctx context.Context, req *proto.AcceptInvitationRequest) (*proto.AcceptInvitationResponse, error)
+	GetActingContext(ctx context.Context, req *proto.GetActingContextRequest) (*proto.GetActingContextResponse, error)
+	CheckEligibility(ctx context.Context, req *proto.CheckEligibilityRequest) (*proto.CheckEligibilityResponse, error)
+	ListPolicies(ctx context.Context, req *proto.ListPoliciesRequest) (*proto.ListPoliciesResponse, error)
 }
 
 type authHandler struct {
 	proto.UnimplementedAuthServiceServer
-	authService services.AuthService
+	authService          services.AuthService
+	authorizationService services.AuthorizationService
+	invitationService    services.InvitationService
+	eligibilityService   services.EligibilityService
+	consentService       services.ConsentService
 }
 
-func NewAuthHandler(userRepo repositories.UserRepository, customerRepo repositories.CustomerRepository, jwtSecret string) *authHandler {
+func NewAuthHandler(authService services.AuthService, authorizationService services.AuthorizationService, invitationService services.InvitationService, eligibilityService services.EligibilityService, consentService services.ConsentService) *authHandler {
 	return &authHandler{
-		authService: services.NewAuthService(userRepo, customerRepo, jwtSecret),
+		authService:          authService,
+		authorizationService: authorizationService,
+		invitationService:    invitationService,
+		eligibilityService:   eligibilityService,
+		consentService:       consentService,
 	}
 }
 
 func (h *authHandler) Register(ctx context.Context, req *proto.RegisterRequest) (*proto.RegisterResponse, error) {
+	ctx, span := tracing.Start(ctx, "authHandler.Register")
+	defer span.End()
+
 	err := h.authService.Register(
+		ctx,
 		req.Username,
 		req.Email,
 		req.Password,
@@ -42,87 +59,176 @@ func (h *authHandler) Register(ctx context.Context, req *proto.RegisterRequest)
 		req.Province,
 		req.PostalCode,
 		req.Country,
+		toConsentInputs(req.Consents),
 	)
 
 	if err != nil {
-		// Convert the app error to proto response
-		if appErr, ok := errors.IsAppError(err); ok {
-			return &proto.RegisterResponse{
-				Success: false,
-				Message: appErr.Message,
-				Error: &proto.Error{
-					Type:    string(appErr.Type),
-					Message: appErr.Message,
-					Details: utils.ConvertMapToKeyValuePairs(appErr.Details),
-				},
-			}, nil
-		}
-		return &proto.RegisterResponse{
-			Success: false,
-			Message: err.Error(),
-			Error: &proto.Error{
-				Type:    string(errors.InternalError),
-				Message: "An unexpected error occurred",
-			},
-		}, nil
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RegisterResponse{Success: false, Message: message, Error: protoErr}, nil
 	}
 
 	return &proto.RegisterResponse{Success: true, Message: "Registered Successfully"}, nil
 }
 
 func (h *authHandler) Login(ctx context.Context, req *proto.LoginRequest) (*proto.LoginResponse, error) {
-	token, userid, username, role, err := h.authService.Login(req.Email, req.Username, req.Password)
+	ctx, span := tracing.Start(ctx, "authHandler.Login")
+	defer span.End()
+
+	result, err := h.authService.Login(ctx, req.Email, req.Username, req.Password)
 
 	if err != nil {
-		// Convert the app error to proto response
-		if appErr, ok := errors.IsAppError(err); ok {
-			return &proto.LoginResponse{
-				Success: false,
-				Message: appErr.Message,
-				Error: &proto.Error{
-					Type:    string(appErr.Type),
-					Message: appErr.Message,
-					Details: utils.ConvertMapToKeyValuePairs(appErr.Details),
-				},
-			}, nil
-		}
-		return &proto.LoginResponse{
-			Success: false,
-			Message: err.Error(),
-			Error: &proto.Error{
-				Type:    string(errors.InternalError),
-				Message: "An unexpected error occurred",
-			},
-		}, nil
-	}
-
-	return &proto.LoginResponse{Success: true, Message: "Logged in Successfully", Token: token, UserId: userid, Username: username, Role: role}, nil
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.LoginResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	response := &proto.LoginResponse{Success: true, Message: "Logged in Successfully", Token: result.Token, UserId: result.UserID, Username: result.Username, Role: result.Role}
+	// The token is then only good for the consent RPCs
+	if len(result.RequiredPolicies) > 0 {
+		response.State = "consent_required"
+		response.RequiredPolicies = toProtoPolicies(result.RequiredPolicies)
+	}
+	return response, nil
 }
 
 func (h *authHandler) VerifyToken(ctx context.Context, req *proto.VerifyTokenRequest) (*proto.VerifyTokenResponse, error) {
-	userid, role, err := h.authService.VerifyToken(req.Token)
+	ctx, span := tracing.Start(ctx, "authHandler.VerifyToken")
+	defer span.End()
+
+	claims, err := h.authService.VerifyToken(ctx, req.Token)
+
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.VerifyTokenResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.VerifyTokenResponse{Success: true, Message: "Token validated", Role: claims.Role, UserId: claims.UserID, Roles: claims.Roles, Permissions: claims.Permissions, StoreId: claims.StoreID, LicensedIn: claims.LicensedIn, SubjectId: claims.Subject, ActorId: claims.Actor, AgeOver: toInt32s(claims.AgeOver)}, nil
+}
+
+func (h *authHandler) CheckPermission(ctx context.Context, req *proto.CheckPermissionRequest) (*proto.CheckPermissionResponse, error) {
+	ctx, span := tracing.Start(ctx, "authHandler.CheckPermission")
+	defer span.End()
+
+	allowed, err := h.authService.CheckPermission(ctx, req.Token, req.Permission)
+
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CheckPermissionResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	message := "Permission granted"
+	if !allowed {
+		message = "Permission denied"
+	}
+
+	return &proto.CheckPermissionResponse{Success: true, Message: message, Allowed: allowed}, nil
+}
+
+func (h *authHandler) Authorize(ctx context.Context, req *proto.AuthorizeRequest) (*proto.AuthorizeResponse, error) {
+	ctx, span := tracing.Start(ctx, "authHandler.Authorize")
+	defer span.End()
+
+	attributes := make(map[string]string, len(req.Attributes))
+	for _, attribute := range req.Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+
+	decision, err := h.authorizationService.Authorize(ctx, req.SubjectToken, req.Action, req.Resource, attributes)
+
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.AuthorizeResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.AuthorizeResponse{Success: true, Message: "Authorization evaluated", Allowed: decision.Allowed, Reason: decision.Reason, PolicyId: decision.PolicyID}, nil
+}
+
+func (h *authHandler) AcceptInvitation(ctx context.Context, req *proto.AcceptInvitationRequest) (*proto.AcceptInvitationResponse, error) {
+	ctx, span := tracing.Start(ctx, "authHandler.AcceptInvitation")
+	defer span.End()
+
+	userID, err := h.invitationService.AcceptInvitation(ctx, req.Code, req.Username, req.Password)
 
 	if err != nil {
-		if appErr, ok := errors.IsAppError(err); ok {
-			return &proto.VerifyTokenResponse{
-				Success: false,
-				Message: appErr.Message,
-				Error: &proto.Error{
-					Type:    string(appErr.Type),
-					Message: appErr.Message,
-					Details: utils.ConvertMapToKeyValuePairs(appErr.Details),
-				},
-			}, nil
-		}
-		return &proto.VerifyTokenResponse{
-			Success: false,
-			Message: err.Error(),
-			Error: &proto.Error{
-				Type:    string(errors.InternalError),
-				Message: "An unexpected error occurred",
-			},
-		}, nil
-	}
-
-	return &proto.VerifyTokenResponse{Success: true, Message: "Token validated", Role: role, UserId: userid}, nil
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.AcceptInvitationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.AcceptInvitationResponse{Success: true, Message: "Invitation accepted", UserId: userID}, nil
+}
+
+func (h *authHandler) GetActingContext(ctx context.Context, req *proto.GetActingContextRequest) (*proto.GetActingContextResponse, error) {
+	ctx, span := tracing.Start(ctx, "authHandler.GetActingContext")
+	defer span.End()
+
+	token, claims, err := h.authService.GetActingContext(ctx, req.Token, req.SubjectId)
+
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetActingContextResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.GetActingContextResponse{
+		Success:     true,
+		Message:     "Acting token issued",
+		Token:       token,
+		SubjectId:   claims.Subject,
+		ActorId:     claims.Actor,
+		Permissions: claims.Permissions,
+		ExpiresAt:   claims.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (h *authHandler) CheckEligibility(ctx context.Context, req *proto.CheckEligibilityRequest) (*proto.CheckEligibilityResponse, error) {
+	ctx, span := tracing.Start(ctx, "authHandler.CheckEligibility")
+	defer span.End()
+
+	claims, err := h.authService.VerifyToken(ctx, req.Token)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CheckEligibilityResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	result, err := h.eligibilityService.CheckEligibility(ctx, claims, req.UserId, req.Rule)
+
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CheckEligibilityResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CheckEligibilityResponse{
+		Success:     true,
+		Message:     "Eligibility checked",
+		Eligible:    result.Eligible,
+		RequiredAge: int32(result.RequiredAge),
+		Reason:      result.Reason,
+	}, nil
+}
+
+func (h *authHandler) ListPolicies(ctx context.Context, req *proto.ListPoliciesRequest) (*proto.ListPoliciesResponse, error) {
+	ctx, span := tracing.Start(ctx, "authHandler.ListPolicies")
+	defer span.End()
+
+	policies, err := h.consentService.ListPolicies(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListPoliciesResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.ListPoliciesResponse{Success: true, Message: "Policies retrieved successfully", Policies: toProtoPolicies(policies)}, nil
+}
+
+func toConsentInputs(consents []*proto.PolicyConsent) []services.ConsentInput {
+	inputs := make([]services.ConsentInput, 0, len(consents))
+	for _, consent := range consents {
+		inputs = append(inputs, services.ConsentInput{Policy: consent.Policy, Version: consent.Version})
+	}
+	return inputs
+}
+
+func toInt32s(values []int) []int32 {
+	result := make([]int32, 0, len(values))
+	for _, value := range values {
+		result = append(result, int32(value))
+	}
+	return result
 }
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+)
+
+type ConsentHandler interface {
+	RecordConsent(ctx context.Context, req *proto.RecordConsentRequest) (*proto.RecordConsentResponse, error)
+	WithdrawConsent(ctx context.Context, req *proto.WithdrawConsentRequest) (*proto.WithdrawConsentResponse, error)
+	GetConsents(ctx context.Context, req *proto.GetConsentsRequest) (*proto.GetConsentsResponse, error)
+}
+
+type consentHandler struct {
+	proto.UnimplementedConsentServiceServer
+	consentService services.ConsentService
+}
+
+func NewConsentHandler(consentService services.ConsentService) *consentHandler {
+	return &consentHandler{
+		consentService: consentService,
+	}
+}
+
+func (h *consentHandler) RecordConsent(ctx context.Context, req *proto.RecordConsentRequest) (*proto.RecordConsentResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RecordConsentResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	consent, err := h.consentService.RecordConsent(ctx, userID, req.Policy, req.Version, req.Source)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RecordConsentResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.RecordConsentResponse{Success: true, Message: "Consent recorded", Consent: toProtoConsent(consent)}, nil
+}
+
+func (h *consentHandler) WithdrawConsent(ctx context.Context, req *proto.WithdrawConsentRequest) (*proto.WithdrawConsentResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.WithdrawConsentResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	consent, err := h.consentService.WithdrawConsent(ctx, userID, req.Policy, req.Source)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.WithdrawConsentResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.WithdrawConsentResponse{Success: true, Message: "Consent withdrawn", Consent: toProtoConsent(consent)}, nil
+}
+
+func (h *consentHandler) GetConsents(ctx context.Context, req *proto.GetConsentsRequest) (*proto.GetConsentsResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetConsentsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	current, history, err := h.consentService.GetConsents(ctx, userID)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetConsentsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	required, err := h.consentService.RequiredConsents(ctx, userID)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetConsentsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.GetConsentsResponse{
+		Success:          true,
+		Message:          "Consents retrieved successfully",
+		Current:          toProtoConsents(current),
+		History:          toProtoConsents(history),
+		RequiredPolicies: toProtoPolicies(required),
+	}, nil
+}
+
+func toProtoConsent(consent *models.Consent) *proto.Consent {
+	return &proto.Consent{
+		Id:        consent.ID.String(),
+		Policy:    consent.Policy,
+		Version:   consent.Version,
+		Granted:   consent.Granted,
+		Source:    consent.Source,
+		CreatedAt: consent.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toProtoConsents(consents []models.Consent) []*proto.Consent {
+	protoConsents := make([]*proto.Consent, 0, len(consents))
+	for i := range consents {
+		protoConsents = append(protoConsents, toProtoConsent(&consents[i]))
+	}
+	return protoConsents
+}
+
+func toProtoPolicy(policy *models.PolicyDocument) *proto.Policy {
+	return &proto.Policy{
+		Id:          policy.ID.String(),
+		Policy:      policy.Policy,
+		Version:     policy.Version,
+		Title:       policy.Title,
+		Url:         policy.URL,
+		Mandatory:   policy.Mandatory,
+		EffectiveAt: policy.EffectiveAt.Format(time.RFC3339),
+	}
+}
+
+func toProtoPolicies(policies []models.PolicyDocument) []*proto.Policy {
+	protoPolicies := make([]*proto.Policy, 0, len(policies))
+	for i := range policies {
+		protoPolicies = append(protoPolicies, toProtoPolicy(&policies[i]))
+	}
+	return protoPolicies
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/interceptors"
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+)
+
+type CredentialHandler interface {
+	SubmitCredential(ctx context.Context, req *proto.SubmitCredentialRequest) (*proto.SubmitCredentialResponse, error)
+	GetMyCredentials(ctx context.Context, req *proto.GetMyCredentialsRequest) (*proto.GetMyCredentialsResponse, error)
+}
+
+type credentialHandler struct {
+	proto.UnimplementedCredentialServiceServer
+	credentialService services.CredentialService
+}
+
+func NewCredentialHandler(credentialService services.CredentialService) *credentialHandler {
+	return &credentialHandler{
+		credentialService: credentialService,
+	}
+}
+
+func (h *credentialHandler) SubmitCredential(ctx context.Context, req *proto.SubmitCredentialRequest) (*proto.SubmitCredentialResponse, error) {
+	credential, err := h.credentialService.SubmitCredential(ctx, interceptors.UserIDFromContext(ctx), req.LicenseNumber, req.IssuingProvince, req.ExpiresOn)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.SubmitCredentialResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.SubmitCredentialResponse{Success: true, Message: "Credential submitted for review", Credential: toProtoCredential(credential)}, nil
+}
+
+func (h *credentialHandler) GetMyCredentials(ctx context.Context, req *proto.GetMyCredentialsRequest) (*proto.GetMyCredentialsResponse, error) {
+	credentials, err := h.credentialService.GetCredentials(ctx, interceptors.UserIDFromContext(ctx))
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetMyCredentialsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoCredentials := make([]*proto.Credential, 0, len(credentials))
+	for i := range credentials {
+		protoCredentials = append(protoCredentials, toProtoCredential(&credentials[i]))
+	}
+
+	return &proto.GetMyCredentialsResponse{Success: true, Message: "Credentials retrieved successfully", Credentials: protoCredentials}, nil
+}
+
+func toProtoCredential(credential *models.ProfessionalCredential) *proto.Credential {
+	protoCredential := &proto.Credential{
+		Id:              credential.ID.String(),
+		UserId:          credential.UserID.String(),
+		LicenseNumber:   credential.LicenseNumber,
+		IssuingProvince: credential.IssuingProvince,
+		ExpiresOn:       credential.ExpiresOn.Format("2006-01-02"),
+		Status:          credential.Status,
+		CreatedAt:       credential.CreatedAt.Format(time.RFC3339),
+	}
+	if credential.ReviewedBy != nil {
+		protoCredential.ReviewedBy = credential.ReviewedBy.String()
+	}
+	if credential.ReviewedAt != nil {
+		protoCredential.ReviewedAt = credential.ReviewedAt.Format(time.RFC3339)
+	}
+	if credential.RejectionReason != nil {
+		protoCredential.RejectionReason = *credential.RejectionReason
+	}
+	return protoCredential
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/interceptors"
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+)
+
+type DelegationHandler interface {
+	CreateDependent(ctx context.Context, req *proto.CreateDependentRequest) (*proto.CreateDependentResponse, error)
+	ListDependents(ctx context.Context, req *proto.ListDependentsRequest) (*proto.ListDependentsResponse, error)
+	GrantDelegation(ctx context.Context, req *proto.GrantDelegationRequest) (*proto.GrantDelegationResponse, error)
+	RevokeDelegation(ctx context.Context, req *proto.RevokeDelegationRequest) (*proto.RevokeDelegationResponse, error)
+	ListDelegations(ctx context.Context, req *proto.ListDelegationsRequest) (*proto.ListDelegationsResponse, error)
+}
+
+type delegationHandler struct {
+	proto.UnimplementedDelegationServiceServer
+	delegationService services.DelegationService
+}
+
+func NewDelegationHandler(delegationService services.DelegationService) *delegationHandler {
+	return &delegationHandler{
+		delegationService: delegationService,
+	}
+}
+
+func (h *delegationHandler) CreateDependent(ctx context.Context, req *proto.CreateDependentRequest) (*proto.CreateDependentResponse, error) {
+	userID, err := ownerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CreateDependentResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	dependent, err := h.delegationService.CreateDependent(ctx, userID, req.FirstName, req.LastName, req.DateOfBirth)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CreateDependentResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CreateDependentResponse{Success: true, Message: "Dependent created successfully", Dependent: toProtoDependent(dependent)}, nil
+}
+
+func (h *delegationHandler) ListDependents(ctx context.Context, req *proto.ListDependentsRequest) (*proto.ListDependentsResponse, error) {
+	userID, err := ownerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListDependentsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	dependents, err := h.delegationService.GetDependents(ctx, userID)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListDependentsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoDependents := make([]*proto.Dependent, 0, len(dependents))
+	for i := range dependents {
+		protoDependents = append(protoDependents, toProtoDependent(&dependents[i]))
+	}
+
+	return &proto.ListDependentsResponse{Success: true, Message: "Dependents retrieved successfully", Dependents: protoDependents}, nil
+}
+
+func (h *delegationHandler) GrantDelegation(ctx context.Context, req *proto.GrantDelegationRequest) (*proto.GrantDelegationResponse, error) {
+	userID, err := ownerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GrantDelegationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	grant, err := h.delegationService.GrantDelegation(ctx, userID, req.Delegate, req.SubjectId, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GrantDelegationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.GrantDelegationResponse{Success: true, Message: "Delegation granted successfully", Grant: toProtoGrant(grant)}, nil
+}
+
+func (h *delegationHandler) RevokeDelegation(ctx context.Context, req *proto.RevokeDelegationRequest) (*proto.RevokeDelegationResponse, error) {
+	userID, err := ownerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RevokeDelegationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	grant, err := h.delegationService.RevokeDelegation(ctx, userID, req.GrantId)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.RevokeDelegationResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.RevokeDelegationResponse{Success: true, Message: "Delegation revoked successfully", Grant: toProtoGrant(grant)}, nil
+}
+
+func (h *delegationHandler) ListDelegations(ctx context.Context, req *proto.ListDelegationsRequest) (*proto.ListDelegationsResponse, error) {
+	userID, err := ownerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListDelegationsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	grants, err := h.delegationService.GetDelegations(ctx, userID)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ListDelegationsResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	protoGrants := make([]*proto.DelegationGrant, 0, len(grants))
+	for i := range grants {
+		protoGrants = append(protoGrants, toProtoGrant(&grants[i]))
+	}
+
+	return &proto.ListDelegationsResponse{Success: true, Message: "Delegations retrieved successfully", Grants: protoGrants}, nil
+}
+
+// ownerFromContext returns the ID of the caller, refusing acting tokens so that
+// delegates cannot create dependents or pass access on
+func ownerFromContext(ctx context.Context) (string, error) {
+	claims, ok := interceptors.ClaimsFromContext(ctx)
+	if !ok {
+		return "", errors.NewAuthError("auth.missing_token")
+	}
+	if claims.IsActing() {
+		return "", errors.NewAuthError("delegation.acting_token")
+	}
+	return claims.UserID, nil
+}
+
+func toProtoDependent(dependent *models.Dependent) *proto.Dependent {
+	protoDependent := &proto.Dependent{
+		Id:        dependent.ID.String(),
+		OwnerId:   dependent.OwnerID.String(),
+		FirstName: dependent.FirstName,
+		LastName:  dependent.LastName,
+		CreatedAt: dependent.CreatedAt.Format(time.RFC3339),
+	}
+	if dependent.DateOfBirth != nil {
+		protoDependent.DateOfBirth = dependent.DateOfBirth.Format(time.RFC3339)
+	}
+	return protoDependent
+}
+
+func toProtoGrant(grant *models.DelegationGrant) *proto.DelegationGrant {
+	protoGrant := &proto.DelegationGrant{
+		Id:          grant.ID.String(),
+		DelegateId:  grant.DelegateID.String(),
+		SubjectId:   grant.SubjectID.String(),
+		SubjectType: grant.SubjectType,
+		Scopes:      grant.ScopeList(),
+		GrantedBy:   grant.GrantedBy.String(),
+		CreatedAt:   grant.CreatedAt.Format(time.RFC3339),
+	}
+	if grant.ExpiresAt != nil {
+		protoGrant.ExpiresAt = grant.ExpiresAt.Format(time.RFC3339)
+	}
+	if grant.RevokedAt != nil {
+		protoGrant.RevokedAt = grant.RevokedAt.Format(time.RFC3339)
+	}
+	return protoGrant
+}
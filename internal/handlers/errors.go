@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/PharmaKart/authentication-svc/internal/interceptors"
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exposeInternalErrors keeps the cause of internal errors in responses
+var exposeInternalErrors bool
+
+// ExposeInternalErrors makes responses include the cause of internal errors. It is meant for development only:
+// causes contain database messages with constraint names and SQL fragments.
+func ExposeInternalErrors(enabled bool) {
+	exposeInternalErrors = enabled
+}
+
+// toProtoError converts an error to the message and error fields of a response, in the language asked for
+// with the "accept-language" metadata. The codes of the message and details are returned alongside the text.
+// Internal errors, and errors that are not application errors, are logged with a generated ID;
+// callers only get a generic message with that ID, which is also returned under error_id.
+func toProtoError(ctx context.Context, err error) (string, *proto.Error) {
+	lang := interceptors.LanguageFromContext(ctx)
+
+	// Failures are returned in the response rather than as a status, so they are recorded on the span here
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+
+	appErr, ok := errors.IsAppError(err)
+	if ok && appErr.Type != errors.InternalError {
+		message, details := appErr.Localize(lang)
+		return message, &proto.Error{
+			Type:        string(appErr.Type),
+			Message:     message,
+			Details:     utils.ConvertMapToKeyValuePairs(details),
+			MessageCode: appErr.MessageCode,
+			DetailCodes: utils.ConvertMapToKeyValuePairs(appErr.DetailCodes),
+		}
+	}
+
+	cause := err.Error()
+	if ok && appErr.Details["internal"] != "" {
+		cause = appErr.Details["internal"]
+	}
+
+	errorID := uuid.NewString()
+	utils.LoggerFromContext(ctx).WithFields(map[string]interface{}{
+		"errorID": errorID,
+		"error":   cause,
+	}).Error("Internal error")
+	span.SetAttributes(attribute.String("error.id", errorID))
+	span.SetStatus(otelcodes.Error, cause)
+
+	details := map[string]string{"error_id": errorID}
+	if exposeInternalErrors {
+		details["internal"] = cause
+	}
+	message := i18n.Translate(lang, i18n.M("error.internal_with_id", "error_id", errorID))
+	return message, &proto.Error{
+		Type:        string(errors.InternalError),
+		Message:     message,
+		Details:     utils.ConvertMapToKeyValuePairs(details),
+		MessageCode: "error.internal",
+	}
+}
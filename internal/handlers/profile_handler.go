@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/interceptors"
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+)
+
+type ProfileHandler interface {
+	GetProfile(ctx context.Context, req *proto.GetProfileRequest) (*proto.GetProfileResponse, error)
+	UpdateProfile(ctx context.Context, req *proto.UpdateProfileRequest) (*proto.UpdateProfileResponse, error)
+	DeleteAccount(ctx context.Context, req *proto.DeleteAccountRequest) (*proto.DeleteAccountResponse, error)
+	CancelAccountDeletion(ctx context.Context, req *proto.CancelAccountDeletionRequest) (*proto.CancelAccountDeletionResponse, error)
+	ExportMyData(ctx context.Context, req *proto.ExportMyDataRequest) (*proto.ExportMyDataResponse, error)
+	GetDataExport(ctx context.Context, req *proto.GetDataExportRequest) (*proto.GetDataExportResponse, error)
+	DownloadDataExport(ctx context.Context, req *proto.DownloadDataExportRequest) (*proto.DownloadDataExportResponse, error)
+}
+
+type profileHandler struct {
+	proto.UnimplementedProfileServiceServer
+	profileService services.ProfileService
+	erasureService services.ErasureService
+	exportService  services.ExportService
+}
+
+func NewProfileHandler(profileService services.ProfileService, erasureService services.ErasureService, exportService services.ExportService) *profileHandler {
+	return &profileHandler{
+		profileService: profileService,
+		erasureService: erasureService,
+		exportService:  exportService,
+	}
+}
+
+func (h *profileHandler) GetProfile(ctx context.Context, req *proto.GetProfileRequest) (*proto.GetProfileResponse, error) {
+	if !interceptors.HasPermission(ctx, "profile:read") {
+		message, protoErr := toProtoError(ctx, errors.NewAuthError("auth.insufficient_permissions"))
+		return &proto.GetProfileResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	profile, err := h.profileService.GetProfile(ctx, interceptors.UserIDFromContext(ctx))
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetProfileResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.GetProfileResponse{Success: true, Message: "Profile retrieved successfully", Profile: toProtoProfile(profile)}, nil
+}
+
+func (h *profileHandler) UpdateProfile(ctx context.Context, req *proto.UpdateProfileRequest) (*proto.UpdateProfileResponse, error) {
+	if !interceptors.HasPermission(ctx, "profile:update") {
+		message, protoErr := toProtoError(ctx, errors.NewAuthError("auth.insufficient_permissions"))
+		return &proto.UpdateProfileResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	// Collect the masked fields. Unknown paths are passed on so that validation reports them.
+	fields := make(map[string]string)
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		fields[path] = profileField(req.GetProfile(), path)
+	}
+
+	profile, err := h.profileService.UpdateProfile(ctx, interceptors.UserIDFromContext(ctx), fields, int(req.Version))
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.UpdateProfileResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.UpdateProfileResponse{Success: true, Message: "Profile updated successfully", Profile: toProtoProfile(profile)}, nil
+}
+
+func (h *profileHandler) DeleteAccount(ctx context.Context, req *proto.DeleteAccountRequest) (*proto.DeleteAccountResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DeleteAccountResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	deletion, err := h.erasureService.RequestDeletion(ctx, userID, req.Password)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DeleteAccountResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.DeleteAccountResponse{
+		Success:      true,
+		Message:      "Account deletion scheduled",
+		ScheduledFor: deletion.ScheduledFor.Format(time.RFC3339),
+	}, nil
+}
+
+func (h *profileHandler) CancelAccountDeletion(ctx context.Context, req *proto.CancelAccountDeletionRequest) (*proto.CancelAccountDeletionResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CancelAccountDeletionResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	if err := h.erasureService.CancelDeletion(ctx, userID); err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.CancelAccountDeletionResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.CancelAccountDeletionResponse{Success: true, Message: "Account deletion cancelled"}, nil
+}
+
+func (h *profileHandler) ExportMyData(ctx context.Context, req *proto.ExportMyDataRequest) (*proto.ExportMyDataResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ExportMyDataResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	export, token, err := h.exportService.ExportMyData(ctx, userID, req.Format)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.ExportMyDataResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.ExportMyDataResponse{Success: true, Message: "Data export started", Export: toProtoDataExport(export), DownloadToken: token}, nil
+}
+
+func (h *profileHandler) GetDataExport(ctx context.Context, req *proto.GetDataExportRequest) (*proto.GetDataExportResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetDataExportResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	export, err := h.exportService.GetDataExport(ctx, userID, req.ExportId)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.GetDataExportResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.GetDataExportResponse{Success: true, Message: "Data export retrieved successfully", Export: toProtoDataExport(export)}, nil
+}
+
+func (h *profileHandler) DownloadDataExport(ctx context.Context, req *proto.DownloadDataExportRequest) (*proto.DownloadDataExportResponse, error) {
+	userID, err := accountOwnerFromContext(ctx)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DownloadDataExportResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	export, err := h.exportService.DownloadDataExport(ctx, userID, req.DownloadToken)
+	if err != nil {
+		message, protoErr := toProtoError(ctx, err)
+		return &proto.DownloadDataExportResponse{Success: false, Message: message, Error: protoErr}, nil
+	}
+
+	return &proto.DownloadDataExportResponse{
+		Success:     true,
+		Message:     "Data export downloaded successfully",
+		FileName:    export.FileName(),
+		ContentType: export.ContentType(),
+		Data:        export.Data,
+	}, nil
+}
+
+// accountOwnerFromContext returns the ID of the caller, refusing acting tokens so that
+// only the account holder can close their account or obtain its data
+func accountOwnerFromContext(ctx context.Context) (string, error) {
+	claims, ok := interceptors.ClaimsFromContext(ctx)
+	if !ok {
+		return "", errors.NewAuthError("auth.missing_token")
+	}
+	if claims.IsActing() {
+		return "", errors.NewAuthError("auth.acting_token_account")
+	}
+	return claims.UserID, nil
+}
+
+// profileField returns the value of the field named by a field mask path
+func profileField(profile *proto.Profile, path string) string {
+	switch path {
+	case "first_name":
+		return profile.GetFirstName()
+	case "last_name":
+		return profile.GetLastName()
+	case "phone":
+		return profile.GetPhone()
+	}
+	return ""
+}
+
+func toProtoDataExport(export *models.DataExport) *proto.DataExport {
+	protoExport := &proto.DataExport{
+		Id:        export.ID.String(),
+		Format:    export.Format,
+		Status:    export.Status,
+		CreatedAt: export.CreatedAt.Format(time.RFC3339),
+	}
+	if export.Error != nil {
+		protoExport.Error = *export.Error
+	}
+	if export.CompletedAt != nil {
+		protoExport.CompletedAt = export.CompletedAt.Format(time.RFC3339)
+	}
+	if export.ExpiresAt != nil {
+		protoExport.ExpiresAt = export.ExpiresAt.Format(time.RFC3339)
+	}
+	return protoExport
+}
+
+func toProtoProfile(profile *services.Profile) *proto.Profile {
+	protoProfile := &proto.Profile{
+		UserId:    profile.UserID.String(),
+		Username:  profile.Username,
+		Email:     profile.Email,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		Version:   int64(profile.Version),
+		UpdatedAt: profile.UpdatedAt.Format(time.RFC3339),
+	}
+	if profile.Phone != nil {
+		protoProfile.Phone = *profile.Phone
+	}
+	if profile.DateOfBirth != nil {
+		protoProfile.DateOfBirth = profile.DateOfBirth.Format(time.RFC3339)
+	}
+	return protoProfile
+}
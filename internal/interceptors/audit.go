@@ -0,0 +1,53 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const callerKey contextKey = "auditedCaller"
+
+// AuditCalls returns an interceptor that records every call to methods starting with methodPrefix
+// in the audit log, with the caller, the user the call is about and whether it succeeded.
+// It must run before the interceptor authenticating those methods, so that calls it refuses are recorded too.
+func AuditCalls(auditService services.AuditService, methodPrefix string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, methodPrefix) {
+			return handler(ctx, req)
+		}
+
+		// Filled in by the authenticating interceptor, whose context this interceptor does not see
+		caller := new(string)
+		resp, err := handler(context.WithValue(ctx, callerKey, caller), req)
+
+		// Requests about a user carry its ID
+		subjectID := ""
+		if r, ok := req.(interface{ GetUserId() string }); ok {
+			subjectID = r.GetUserId()
+		}
+
+		details := map[string]interface{}{"success": err == nil}
+		if err != nil {
+			details["code"] = status.Code(err).String()
+		} else if r, ok := resp.(interface{ GetSuccess() bool }); ok && !r.GetSuccess() {
+			details["success"] = false
+		}
+
+		action := "admin." + info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		auditService.Record(ctx, action, *caller, subjectID, details)
+
+		return resp, err
+	}
+}
+
+// recordCaller tells the enclosing AuditCalls interceptor, if any, who the authenticated caller is
+func recordCaller(ctx context.Context, claims *utils.TokenClaims) {
+	if caller, ok := ctx.Value(callerKey).(*string); ok {
+		*caller = claims.UserID
+	}
+}
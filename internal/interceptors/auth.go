@@ -0,0 +1,153 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/internal/services"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey string
+
+const claimsKey contextKey = "claims"
+
+// RequireAuthentication returns an interceptor that only lets calls to methods starting with
+// methodPrefix through when the "authorization" metadata holds a valid token.
+// The caller's token claims are stored in the context.
+func RequireAuthentication(authService services.AuthService, methodPrefix string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, methodPrefix) {
+			return handler(ctx, req)
+		}
+
+		claims, err := authenticate(ctx, authService.VerifyToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(context.WithValue(ctx, claimsKey, claims), req)
+	}
+}
+
+// RequireConsentAuthentication is RequireAuthentication for the consent RPCs, which also accept the tokens
+// Login issues to users who have mandatory policies to accept
+func RequireConsentAuthentication(authService services.AuthService, methodPrefix string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, methodPrefix) {
+			return handler(ctx, req)
+		}
+
+		claims, err := authenticate(ctx, authService.VerifyConsentToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(context.WithValue(ctx, claimsKey, claims), req)
+	}
+}
+
+// RequireRole returns an interceptor that only lets calls to methods starting with
+// methodPrefix through when the "authorization" metadata holds a valid token of a user with the given role.
+// The caller's token claims are stored in the context.
+func RequireRole(authService services.AuthService, methodPrefix, role string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, methodPrefix) {
+			return handler(ctx, req)
+		}
+
+		claims, err := authenticate(ctx, authService.VerifyToken)
+		if err != nil {
+			return nil, err
+		}
+
+		if !hasRole(claims, role) {
+			return nil, status.Error(codes.PermissionDenied, i18n.Translate(LanguageFromContext(ctx), i18n.M("auth.insufficient_permissions")))
+		}
+
+		return handler(context.WithValue(ctx, claimsKey, claims), req)
+	}
+}
+
+func authenticate(ctx context.Context, verify func(context.Context, string) (*utils.TokenClaims, error)) (*utils.TokenClaims, error) {
+	token := TokenFromContext(ctx)
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, i18n.Translate(LanguageFromContext(ctx), i18n.M("auth.missing_token")))
+	}
+
+	claims, err := verify(ctx, token)
+	if err != nil {
+		// Tell users holding a consent token why it is refused
+		key := "auth.invalid_token"
+		if appErr, ok := errors.IsAppError(err); ok && appErr.MessageCode == "auth.consent_required" {
+			key = appErr.MessageCode
+		}
+		return nil, status.Error(codes.Unauthenticated, i18n.Translate(LanguageFromContext(ctx), i18n.M(key)))
+	}
+
+	recordCaller(ctx, claims)
+	return claims, nil
+}
+
+// TokenFromContext returns the bearer token from the incoming "authorization" metadata
+func TokenFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	token := strings.TrimSpace(values[0])
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	return token
+}
+
+// ClaimsFromContext returns the token claims of the authenticated caller
+func ClaimsFromContext(ctx context.Context) (*utils.TokenClaims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*utils.TokenClaims)
+	return claims, ok
+}
+
+// UserIDFromContext returns the ID of the authenticated caller
+func UserIDFromContext(ctx context.Context) string {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return claims.UserID
+}
+
+// HasPermission reports whether the authenticated caller's token grants the permission
+func HasPermission(ctx context.Context, permission string) bool {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, p := range claims.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRole(claims *utils.TokenClaims, role string) bool {
+	for _, r := range claims.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
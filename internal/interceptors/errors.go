@@ -0,0 +1,50 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// failureResponse is implemented by responses that report failures in their success and error fields
+type failureResponse interface {
+	GetSuccess() bool
+	GetError() *proto.Error
+}
+
+// StatusErrors returns an interceptor that turns failed responses into gRPC status errors,
+// with the code of the application error and its fields as BadRequest details for validation failures.
+// The message is the localized one of the response, and the message codes are kept as ErrorInfo details.
+// It must run last so that the interceptors before it see the status.
+func StatusErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		failed, ok := resp.(failureResponse)
+		if !ok || failed.GetSuccess() || failed.GetError() == nil {
+			return resp, nil
+		}
+
+		protoErr := failed.GetError()
+		return nil, &errors.AppError{
+			Type:        errors.ErrorType(protoErr.Type),
+			MessageCode: protoErr.MessageCode,
+			Message:     protoErr.Message,
+			Details:     toMap(protoErr.Details),
+			DetailCodes: toMap(protoErr.DetailCodes),
+		}
+	}
+}
+
+func toMap(pairs []*proto.KeyValuePair) map[string]string {
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		m[pair.Key] = pair.Value
+	}
+	return m
+}
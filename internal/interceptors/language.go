@@ -0,0 +1,24 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"google.golang.org/grpc/metadata"
+)
+
+// LanguageFromContext returns the supported language that best matches the incoming "accept-language" metadata,
+// which takes the form of an Accept-Language header, e.g. "fr-CA,fr;q=0.9,en;q=0.8"
+func LanguageFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return i18n.DefaultLanguage
+	}
+
+	values := md.Get("accept-language")
+	if len(values) == 0 {
+		return i18n.DefaultLanguage
+	}
+	return i18n.Match(strings.Join(values, ","))
+}
@@ -0,0 +1,65 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// LogCalls returns an interceptor that logs every call with its method, duration, status code and peer.
+// Calls failing with a server-side code are logged as errors. Failures reported in the response fields
+// of legacy clients also get the error type.
+func LogCalls() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		fields := callFields(ctx, info.FullMethod, start, err)
+		if failed, ok := resp.(failureResponse); ok && !failed.GetSuccess() && failed.GetError() != nil {
+			fields["errorType"] = failed.GetError().Type
+		}
+		logCall(ctx, fields, err)
+
+		return resp, err
+	}
+}
+
+// StreamLogCalls is LogCalls for streaming calls
+func StreamLogCalls() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+
+		logCall(ss.Context(), callFields(ss.Context(), info.FullMethod, start, err), err)
+
+		return err
+	}
+}
+
+func callFields(ctx context.Context, method string, start time.Time, err error) logrus.Fields {
+	fields := logrus.Fields{
+		"method":     method,
+		"durationMs": float64(time.Since(start).Microseconds()) / 1000,
+		"code":       status.Code(err).String(),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields["peer"] = p.Addr.String()
+	}
+	return fields
+}
+
+func logCall(ctx context.Context, fields logrus.Fields, err error) {
+	logger := utils.LoggerFromContext(ctx).WithFields(fields)
+	switch status.Code(err) {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		logger.WithField("error", err.Error()).Error("gRPC call failed")
+	default:
+		logger.Info("gRPC call")
+	}
+}
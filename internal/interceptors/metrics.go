@@ -0,0 +1,39 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/metrics"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// CollectMetrics returns an interceptor that counts calls and records their duration by method and status code.
+// Failures returned in the response fields for legacy clients are counted with the code they would have
+// as status errors, so that failure ratios do not depend on LEGACY_ERROR_RESPONSES.
+func CollectMetrics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		if failed, ok := resp.(failureResponse); ok && err == nil && !failed.GetSuccess() && failed.GetError() != nil {
+			code = (&errors.AppError{Type: errors.ErrorType(failed.GetError().Type)}).Code()
+		}
+		metrics.ObserveCall(info.FullMethod, code.String(), time.Since(start))
+
+		return resp, err
+	}
+}
+
+// StreamCollectMetrics is CollectMetrics for streaming calls
+func StreamCollectMetrics() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		metrics.ObserveCall(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,53 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoverPanics returns an interceptor that turns a panic in a handler into an Internal error instead of
+// crashing the server. The panic is logged with its stack trace and a generated error ID, which is the only
+// thing the caller gets, as for other internal errors.
+func RecoverPanics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(ctx, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoverPanics is RecoverPanics for streaming calls
+func StreamRecoverPanics() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(ss.Context(), info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recoveredError(ctx context.Context, method string, recovered interface{}) error {
+	errorID := uuid.NewString()
+	utils.LoggerFromContext(ctx).WithFields(map[string]interface{}{
+		"errorID": errorID,
+		"method":  method,
+		"panic":   fmt.Sprint(recovered),
+		"stack":   string(debug.Stack()),
+	}).Error("Recovered from panic")
+
+	message := i18n.Translate(LanguageFromContext(ctx), i18n.M("error.internal_with_id", "error_id", errorID))
+	return status.Error(codes.Internal, message)
+}
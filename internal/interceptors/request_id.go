@@ -0,0 +1,75 @@
+package interceptors
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the metadata key carrying the ID that correlates the log lines of a call across services
+const RequestIDHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// requestIDPattern limits the request IDs accepted from callers, so that they cannot forge log lines
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID returns an interceptor that gives every call the request ID sent by the caller, or a new one,
+// and a logger carrying it in its context. The ID is sent back in the response header.
+// It must run first so that the other interceptors log with the ID.
+func RequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// StreamRequestID is RequestID for streaming calls
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+// RequestIDFromContext returns the request ID of the call, or "" outside of a call
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 && requestIDPattern.MatchString(values[0]) {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+
+	// Send the ID back, so that callers which did not set one can still quote it
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	logger := utils.LoggerFromContext(ctx).WithField("requestID", requestID)
+	// Tie the log lines to the trace of the call, when it is recorded
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsSampled() {
+		logger = logger.WithField("traceID", spanContext.TraceID().String())
+	}
+	return utils.WithLogger(ctx, logger)
+}
+
+// contextStream replaces the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,151 @@
+// Package metrics collects the Prometheus metrics of the service and serves them over HTTP
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pharmakart_auth"
+
+// registry holds the metrics of the service, the Go runtime and the process
+var registry = prometheus.NewRegistry()
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Time taken to handle gRPC calls, by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by outcome and primary role of the user (unknown when no user matched).",
+	}, []string{"outcome", "role"})
+
+	registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Customer registrations, by outcome.",
+	}, []string{"outcome"})
+
+	tokenVerifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_verifications_total",
+		Help:      "Access token verifications, by result.",
+	}, []string{"result"})
+
+	lockouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "lockouts_total",
+		Help:      "Logins refused because the account is disabled, after the password was checked.",
+	})
+
+	passwordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "password_hash_duration_seconds",
+		Help:      "Time taken by bcrypt to hash a password or compare one with a hash.",
+		Buckets:   []float64{.01, .025, .05, .075, .1, .15, .2, .3, .5, 1},
+	}, []string{"operation"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcRequests,
+		rpcDuration,
+		logins,
+		registrations,
+		tokenVerifications,
+		lockouts,
+		passwordHashDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB adds the connection pool statistics of the database, such as open, in-use and idle connections
+// and the time spent waiting for one
+func RegisterDB(db *sql.DB, name string) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveCall records a gRPC call, with method being the full method name, e.g. /auth.AuthService/Login
+func ObserveCall(method, code string, duration time.Duration) {
+	rpcRequests.WithLabelValues(method, code).Inc()
+	rpcDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// Login outcomes
+const (
+	LoginSucceeded         = "success"
+	LoginUnknownUser       = "unknown_user"
+	LoginIncorrectPassword = "incorrect_password"
+	LoginAccountDisabled   = "account_disabled"
+	LoginError             = "error"
+)
+
+// ObserveLogin records a login attempt. The role is the primary role of the user, empty when no user matched.
+func ObserveLogin(outcome, role string) {
+	if role == "" {
+		role = "unknown"
+	}
+	logins.WithLabelValues(outcome, role).Inc()
+	if outcome == LoginAccountDisabled {
+		lockouts.Inc()
+	}
+}
+
+// ObserveRegistration records a registration with its outcome, derived from the error it returned
+func ObserveRegistration(err error) {
+	registrations.WithLabelValues(Outcome(err)).Inc()
+}
+
+// Token verification results
+const (
+	TokenValid    = "valid"
+	TokenInvalid  = "invalid"  // bad signature, expired or malformed
+	TokenDisabled = "disabled" // the user, or the actor of an acting token, is disabled or gone
+	TokenRevoked  = "revoked"  // the delegation behind an acting token no longer holds
+)
+
+// ObserveTokenVerification records the result of an access token verification
+func ObserveTokenVerification(result string) {
+	tokenVerifications.WithLabelValues(result).Inc()
+}
+
+// ObservePasswordHash records the time bcrypt took, operation being hash or compare
+func ObservePasswordHash(operation string, duration time.Duration) {
+	passwordHashDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// Outcome returns "success" for a nil error, and otherwise the type of the application error,
+// e.g. "validation" or "conflict", or "internal" for other errors
+func Outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	if appErr, ok := errors.IsAppError(err); ok {
+		return strings.TrimSuffix(strings.ToLower(string(appErr.Type)), "_error")
+	}
+	return "internal"
+}
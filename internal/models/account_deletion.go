@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountDeletion is a request to erase an account. Self-service requests wait out a grace period
+// during which the user can cancel; admin requests are scheduled immediately.
+type AccountDeletion struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	RequestedBy  uuid.UUID  `gorm:"type:uuid;not null"`
+	ScheduledFor time.Time  `gorm:"type:timestamptz;not null;index"`
+	CancelledAt  *time.Time `gorm:"type:timestamptz"`
+	CompletedAt  *time.Time `gorm:"type:timestamptz"`
+	CreatedAt    time.Time  `gorm:"type:timestamptz;default:now()"`
+}
+
+func (d *AccountDeletion) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
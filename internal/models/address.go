@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Address is a labelled delivery or billing address of a customer.
+// A customer has at most one default shipping and one default billing address.
+type Address struct {
+	ID                uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CustomerID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	Label             string    `gorm:"type:varchar(50);not null"` // e.g. Home, Work, Cottage
+	StreetLine1       string    `gorm:"not null"`
+	StreetLine2       *string
+	City              string    `gorm:"not null"`
+	Province          string    `gorm:"not null"`
+	PostalCode        string    `gorm:"not null"`
+	Country           string    `gorm:"not null;default:Canada"`
+	IsDefaultShipping bool      `gorm:"not null;default:false"`
+	IsDefaultBilling  bool      `gorm:"not null;default:false"`
+	CreatedAt         time.Time `gorm:"type:timestamptz;default:now()"`
+	UpdatedAt         time.Time `gorm:"type:timestamptz;default:now()"`
+}
+
+func (a *Address) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditGenesisHash is the previous hash of the first event in the chain
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+// AuditEvent records a security-relevant action. Details must not contain personal information.
+// Events form a hash chain: each one includes the hash of the event before it, so editing,
+// removing or reordering events breaks the chain from that point on.
+type AuditEvent struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Sequence  int64      `gorm:"type:bigint;uniqueIndex"`          // position in the chain, starting at 1
+	Action    string     `gorm:"type:varchar(100);not null;index"` // e.g. account.erased
+	ActorID   *uuid.UUID `gorm:"type:uuid;index"`                  // nil for system jobs and the CLI
+	SubjectID *uuid.UUID `gorm:"type:uuid;index"`
+	Details   string     `gorm:"type:jsonb;not null;default:'{}'"`
+	PrevHash  string     `gorm:"type:char(64);not null;default:''"`
+	Hash      string     `gorm:"type:char(64);not null;default:''"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;default:now();index"`
+}
+
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
+
+// ComputeHash returns the SHA-256 of the event's content and previous hash. The details are
+// re-encoded with sorted keys because the database does not keep the JSON text as written.
+func (e *AuditEvent) ComputeHash() (string, error) {
+	details := map[string]interface{}{}
+	if e.Details != "" {
+		if err := json.Unmarshal([]byte(e.Details), &details); err != nil {
+			return "", err
+		}
+	}
+	canonical, err := json.Marshal(details)
+	if err != nil {
+		return "", err
+	}
+
+	content := fmt.Sprintf("%d|%s|%s|%s|%s|%s|%s",
+		e.Sequence,
+		e.PrevHash,
+		e.Action,
+		uuidString(e.ActorID),
+		uuidString(e.SubjectID),
+		canonical,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuthorizationDecision struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	Action     string    `gorm:"not null"`
+	Resource   string    `gorm:"not null"`
+	Attributes string    `gorm:"type:jsonb;not null;default:'{}'"`
+	Allowed    bool      `gorm:"not null"`
+	Reason     string    `gorm:"not null"`
+	PolicyID   *string
+	CreatedAt  time.Time `gorm:"type:timestamptz;default:now();index"`
+}
+
+func (d *AuthorizationDecision) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PolicyDocument is one version of a policy customers consent to, such as the privacy policy.
+// The current version of a policy is the latest one in effect.
+type PolicyDocument struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Policy      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_policy_documents_version"` // e.g. privacy_policy
+	Version     string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_policy_documents_version"`
+	Title       string    `gorm:"not null"`
+	URL         string    `gorm:"not null;default:''"`
+	Mandatory   bool      `gorm:"not null;default:false"` // mandatory policies must be accepted to use the account
+	EffectiveAt time.Time `gorm:"type:timestamptz;not null"`
+	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()"`
+}
+
+func (p *PolicyDocument) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
+
+// Consent is an entry of the consent ledger. Entries are only ever added: withdrawing consent
+// adds an entry with Granted false, so the ledger shows what the user agreed to at any time.
+type Consent struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	PolicyID  uuid.UUID `gorm:"type:uuid;not null"`
+	Policy    string    `gorm:"type:varchar(50);not null"`
+	Version   string    `gorm:"type:varchar(20);not null"`
+	Granted   bool      `gorm:"not null"`
+	Source    string    `gorm:"type:varchar(50);not null"` // where the choice was made, e.g. registration or web
+	CreatedAt time.Time `gorm:"type:timestamptz;default:now()"`
+}
+
+func (c *Consent) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return
+}
+
+// DefaultPolicies are created when no version of the policy exists yet
+var DefaultPolicies = []PolicyDocument{
+	{Policy: "privacy_policy", Version: "1.0", Title: "Privacy Policy", Mandatory: true},
+	{Policy: "terms_of_service", Version: "1.0", Title: "Terms of Service", Mandatory: true},
+	{Policy: "marketing", Version: "1.0", Title: "Marketing Communications"},
+	{Policy: "refill_reminders", Version: "1.0", Title: "Prescription Refill Reminders"},
+}
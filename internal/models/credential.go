@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	CredentialPending  = "pending"
+	CredentialVerified = "verified"
+	CredentialRejected = "rejected"
+	CredentialExpired  = "expired"
+)
+
+type ProfessionalCredential struct {
+	ID              uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+	LicenseNumber   string     `gorm:"type:varchar(50);not null"`
+	IssuingProvince string     `gorm:"type:char(2);not null"`
+	ExpiresOn       time.Time  `gorm:"type:date;not null"`
+	Status          string     `gorm:"type:varchar(20);not null;default:pending;check:status IN ('pending', 'verified', 'rejected', 'expired')"`
+	ReviewedBy      *uuid.UUID `gorm:"type:uuid"`
+	ReviewedAt      *time.Time `gorm:"type:timestamptz"`
+	RejectionReason *string
+	ExpiryFlaggedAt *time.Time `gorm:"type:timestamptz"` // set once the license is about to expire
+	CreatedAt       time.Time  `gorm:"type:timestamptz;default:now()"`
+	UpdatedAt       time.Time  `gorm:"type:timestamptz;default:now()"`
+}
+
+func (c *ProfessionalCredential) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return
+}
@@ -14,13 +14,10 @@ type Customer struct {
 	LastName    string    `gorm:"not null"`
 	Phone       *string
 	DateOfBirth *time.Time
-	StreetLine1 string `gorm:"not null"`
-	StreetLine2 *string
-	City        string    `gorm:"not null"`
-	Province    string    `gorm:"not null"`
-	PostalCode  string    `gorm:"not null"`
-	Country     string    `gorm:"not null;default:Canada"`
+	Version     int       `gorm:"not null;default:1"` // incremented on every update for optimistic concurrency
+	Addresses   []Address `gorm:"foreignKey:CustomerID"`
 	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()"`
+	UpdatedAt   time.Time `gorm:"type:timestamptz;default:now()"`
 }
 
 func (c *Customer) BeforeCreate(tx *gorm.DB) (err error) {
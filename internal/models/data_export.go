@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ExportPending   = "pending"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+)
+
+const (
+	ExportFormatJSON = "json"
+	ExportFormatZip  = "zip"
+)
+
+// DataExport is a job gathering the personal information held about a user into a downloadable document
+type DataExport struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Format      string     `gorm:"type:varchar(10);not null;check:format IN ('json', 'zip')"`
+	Status      string     `gorm:"type:varchar(20);not null;default:pending;check:status IN ('pending', 'running', 'completed', 'failed')"`
+	TokenHash   string     `gorm:"not null;uniqueIndex"` // SHA-256 of the download token, the token itself is never stored
+	Data        []byte     `gorm:"type:bytea"`
+	Error       *string    // reason the export failed, without personal information
+	ExpiresAt   *time.Time `gorm:"type:timestamptz;index"` // the download token is valid until then, set on completion
+	CompletedAt *time.Time `gorm:"type:timestamptz"`
+	CreatedAt   time.Time  `gorm:"type:timestamptz;default:now()"`
+}
+
+func (e *DataExport) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
+
+// FileName returns the name the export is downloaded as
+func (e *DataExport) FileName() string {
+	return "pharmakart-export-" + e.CreatedAt.Format("20060102") + "." + e.Format
+}
+
+// ContentType returns the media type of the export document
+func (e *DataExport) ContentType() string {
+	if e.Format == ExportFormatZip {
+		return "application/zip"
+	}
+	return "application/json"
+}
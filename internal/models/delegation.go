@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	SubjectUser      = "user"
+	SubjectDependent = "dependent"
+)
+
+// Dependent is a person without a login of their own, such as a child or an elderly relative,
+// whose prescriptions and orders are managed by the owning caregiver
+type Dependent struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	OwnerID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	FirstName   string    `gorm:"not null"`
+	LastName    string    `gorm:"not null"`
+	DateOfBirth *time.Time
+	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()"`
+}
+
+func (d *Dependent) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
+
+// DelegationGrant lets the delegate act on behalf of a user or dependent with the granted scopes
+type DelegationGrant struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	DelegateID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	SubjectID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	SubjectType string     `gorm:"type:varchar(20);not null;check:subject_type IN ('user', 'dependent')"`
+	Scopes      string     `gorm:"not null"` // space-separated permissions
+	GrantedBy   uuid.UUID  `gorm:"type:uuid;not null"`
+	ExpiresAt   *time.Time `gorm:"type:timestamptz"` // nil grants never expire
+	RevokedAt   *time.Time `gorm:"type:timestamptz"`
+	CreatedAt   time.Time  `gorm:"type:timestamptz;default:now()"`
+}
+
+func (g *DelegationGrant) BeforeCreate(tx *gorm.DB) (err error) {
+	g.ID = uuid.New()
+	return
+}
+
+// ScopeList returns the granted permissions
+func (g *DelegationGrant) ScopeList() []string {
+	return strings.Fields(g.Scopes)
+}
+
+// Active reports whether the grant is neither revoked nor expired
+func (g *DelegationGrant) Active(now time.Time) bool {
+	return g.RevokedAt == nil && (g.ExpiresAt == nil || now.Before(*g.ExpiresAt))
+}
+
+// DelegableScopes are the permissions that can be delegated. Caregivers act as the customer,
+// so only customer permissions can be granted.
+var DelegableScopes = []string{
+	"profile:read", "profile:update", "orders:create", "orders:read_own", "prescriptions:upload",
+}
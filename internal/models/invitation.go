@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Invitation struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Email          string     `gorm:"not null;index"`
+	Role           string     `gorm:"type:varchar(50);not null"`
+	StoreID        *string    `gorm:"type:varchar(64)"`
+	CodeHash       string     `gorm:"not null;uniqueIndex"` // SHA-256 of the invite code, the code itself is never stored
+	InvitedBy      uuid.UUID  `gorm:"type:uuid;not null"`
+	ExpiresAt      time.Time  `gorm:"type:timestamptz;not null"`
+	SentAt         time.Time  `gorm:"type:timestamptz;not null"`
+	AcceptedAt     *time.Time `gorm:"type:timestamptz"`
+	AcceptedUserID *uuid.UUID `gorm:"type:uuid"`
+	RevokedAt      *time.Time `gorm:"type:timestamptz"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:now()"`
+}
+
+func (i *Invitation) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.New()
+	return
+}
+
+// Status returns pending, accepted, revoked or expired
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return "accepted"
+	case i.RevokedAt != nil:
+		return "revoked"
+	case now.After(i.ExpiresAt):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
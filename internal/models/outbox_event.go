@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Domain events published to other services
+const (
+	EventUserRegistered  = "UserRegistered"
+	EventUserUpdated     = "UserUpdated"
+	EventPasswordChanged = "PasswordChanged"
+	EventUserDisabled    = "UserDisabled"
+	EventUserDeleted     = "UserDeleted"
+)
+
+// EventTypes are the domain events that can be subscribed to
+var EventTypes = []string{
+	EventUserRegistered, EventUserUpdated, EventPasswordChanged, EventUserDisabled, EventUserDeleted,
+}
+
+// OutboxEvent is a domain event waiting to be published. It is written in the same transaction
+// as the change it describes, so an event exists if and only if the change was committed.
+type OutboxEvent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"` // consumers use it to drop duplicates
+	Type          string     `gorm:"type:varchar(50);not null"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Payload       string     `gorm:"type:jsonb;not null;default:'{}'"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"type:timestamptz;not null;default:now();index"`
+	LastError     *string    // reason the last attempt failed
+	PublishedAt   *time.Time `gorm:"type:timestamptz;index"`
+	FailedAt      *time.Time `gorm:"type:timestamptz"` // set when the relay gave up
+	CreatedAt     time.Time  `gorm:"type:timestamptz;default:now()"`
+}
+
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
+
+// NewOutboxEvent returns an event about the user with the given data
+func NewOutboxEvent(eventType string, userID uuid.UUID, data map[string]interface{}) *OutboxEvent {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = []byte("{}")
+	}
+
+	return &OutboxEvent{
+		Type:          eventType,
+		UserID:        userID,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now(),
+	}
+}
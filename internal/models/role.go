@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Role struct {
+	ID          uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name        string       `gorm:"unique;not null;type:varchar(50)"`
+	Description string       `gorm:"not null;default:''"`
+	System      bool         `gorm:"not null;default:false"` // built-in roles cannot be deleted
+	Permissions []Permission `gorm:"many2many:role_permissions;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time    `gorm:"type:timestamptz;default:now()"`
+}
+
+func (r *Role) BeforeCreate(tx *gorm.DB) (err error) {
+	// Keep the ID of existing roles that GORM upserts when saving associations
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
+
+type Permission struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name        string    `gorm:"unique;not null;type:varchar(100)"`
+	Description string    `gorm:"not null;default:''"`
+	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()"`
+}
+
+func (p *Permission) BeforeCreate(tx *gorm.DB) (err error) {
+	// Keep the ID of existing permissions that GORM upserts when saving associations
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return
+}
+
+type UserRole struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RoleID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `gorm:"type:timestamptz;default:now()"`
+}
+
+// DefaultPermissions are the permissions known to the platform
+var DefaultPermissions = map[string]string{
+	"users:read":            "View user accounts",
+	"users:manage":          "Create, update, disable and delete user accounts",
+	"roles:manage":          "Create roles and assign them to users",
+	"profile:read":          "View own profile",
+	"profile:update":        "Update own profile",
+	"products:manage":       "Manage the product catalog",
+	"orders:create":         "Place orders",
+	"orders:read_own":       "View own orders",
+	"orders:read":           "View all orders",
+	"orders:fulfil":         "Prepare and fulfil orders",
+	"prescriptions:upload":  "Upload prescriptions",
+	"prescriptions:read":    "View prescriptions",
+	"prescriptions:approve": "Approve or reject prescriptions",
+	"deliveries:read":       "View assigned deliveries",
+	"deliveries:update":     "Update delivery status",
+	"support:manage":        "Handle customer support requests",
+	"eligibility:check":     "Check the age eligibility of any user or dependent",
+}
+
+// DefaultRoles are the built-in roles and their initial permissions.
+// The admin role always holds every permission.
+var DefaultRoles = map[string][]string{
+	"customer": {
+		"profile:read", "profile:update", "orders:create", "orders:read_own", "prescriptions:upload",
+	},
+	"admin": nil,
+	"pharmacist": {
+		"profile:read", "profile:update", "orders:read", "orders:fulfil", "prescriptions:read", "prescriptions:approve",
+	},
+	"pharmacy_technician": {
+		"profile:read", "profile:update", "orders:read", "orders:fulfil", "prescriptions:read",
+	},
+	"delivery_driver": {
+		"profile:read", "profile:update", "deliveries:read", "deliveries:update",
+	},
+	"support_agent": {
+		"profile:read", "profile:update", "users:read", "orders:read", "support:manage",
+	},
+}
@@ -0,0 +1,12 @@
+package models
+
+import (
+	"time"
+)
+
+type SigningKey struct {
+	ID        string     `gorm:"type:varchar(32);primaryKey"`
+	Secret    string     `gorm:"not null"`
+	RetiredAt *time.Time `gorm:"type:timestamptz"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;default:now()"`
+}
@@ -8,12 +8,16 @@ import (
 )
 
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Username     string    `gorm:"unique;not null;type:varchar(50)"`
-	Email        string    `gorm:"unique;not null"`
-	PasswordHash string    `gorm:"not null"`
-	Role         string    `gorm:"type:varchar(50);not null;check:role IN ('customer', 'admin')"`
-	CreatedAt    time.Time `gorm:"type:timestamptz;default:now()"`
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Username      string     `gorm:"unique;not null;type:varchar(50)"`
+	Email         string     `gorm:"unique;not null"`
+	PasswordHash  string     `gorm:"not null"`
+	Role          string     `gorm:"type:varchar(50);not null"` // primary role, also held in user_roles
+	StoreID       *string    `gorm:"type:varchar(64)"`          // store a staff member works at
+	DisabledAt    *time.Time `gorm:"type:timestamptz"`
+	ErasedAt      *time.Time `gorm:"type:timestamptz"`       // set when the account was erased, the row is kept as a tombstone
+	ConsentExempt bool       `gorm:"not null;default:false"` // created from the CLI, logs in without accepting the mandatory policies
+	CreatedAt     time.Time  `gorm:"type:timestamptz;default:now()"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookSubscription sends the domain events it selects to a partner's endpoint, signed with its secret
+type WebhookSubscription struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	URL         string    `gorm:"not null"`
+	Description string    `gorm:"type:varchar(200);not null;default:''"`
+	EventTypes  string    `gorm:"not null;default:''"`       // space-separated event types, empty for all events
+	Secret      string    `gorm:"type:varchar(64);not null"` // HMAC-SHA256 key, kept in clear to sign deliveries
+	Active      bool      `gorm:"not null;default:true"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()"`
+	UpdatedAt   time.Time `gorm:"type:timestamptz;default:now()"`
+}
+
+func (s *WebhookSubscription) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New()
+	return
+}
+
+// EventTypeList returns the event types the subscription selects, empty for all
+func (s *WebhookSubscription) EventTypeList() []string {
+	return strings.Fields(s.EventTypes)
+}
+
+// Matches reports whether the subscription wants events of the given type
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	types := s.EventTypeList()
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is an event waiting to be sent to a subscription.
+// Delivered rows are kept for a while, failed ones move to the dead-letter table.
+type WebhookDelivery struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_event"`
+	EventID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_event"` // one delivery per event and subscription
+	EventType      string    `gorm:"type:varchar(50);not null"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index"` // user the event is about
+	Payload        string    `gorm:"type:jsonb;not null"`      // the event envelope, sent as the request body
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"type:timestamptz;not null;default:now();index"`
+	LastStatus     int       `gorm:"not null;default:0"` // HTTP status of the last attempt, 0 when no response
+	LastError      *string
+	DeliveredAt    *time.Time `gorm:"type:timestamptz;index"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:now()"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
+
+// WebhookDeadLetter is a delivery that failed every attempt. Replaying it queues a new delivery.
+type WebhookDeadLetter struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID `gorm:"type:uuid;not null"`
+	EventType      string    `gorm:"type:varchar(50);not null"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index"`
+	Payload        string    `gorm:"type:jsonb;not null"`
+	Attempts       int       `gorm:"not null"`
+	LastStatus     int       `gorm:"not null;default:0"`
+	LastError      *string
+	ReplayedAt     *time.Time `gorm:"type:timestamptz"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:now();index"`
+}
+
+func (d *WebhookDeadLetter) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
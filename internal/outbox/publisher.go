@@ -0,0 +1,158 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+)
+
+// webhookTimeout bounds a single delivery to the webhook
+const webhookTimeout = 10 * time.Second
+
+// Message is the envelope every event is published in. Delivery is at least once,
+// so consumers should drop messages whose ID they have already handled.
+type Message struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     string          `json:"user_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewMessage returns the envelope of an outbox event
+func NewMessage(event *models.OutboxEvent) Message {
+	data := json.RawMessage(event.Payload)
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	return Message{
+		ID:         event.ID.String(),
+		Type:       event.Type,
+		UserID:     event.UserID.String(),
+		OccurredAt: event.CreatedAt.UTC(),
+		Data:       data,
+	}
+}
+
+// Publisher delivers messages to other services
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+// New returns the publisher for the given sink: stdout, file (target is the path) or webhook (target is the URL)
+func New(sink, target string) (Publisher, error) {
+	switch sink {
+	case "stdout":
+		return NewWriterPublisher(os.Stdout), nil
+	case "file":
+		if target == "" {
+			return nil, fmt.Errorf("the file sink needs a path")
+		}
+		return NewFilePublisher(target)
+	case "webhook":
+		if target == "" {
+			return nil, fmt.Errorf("the webhook sink needs a URL")
+		}
+		return NewWebhookPublisher(target), nil
+	default:
+		return nil, fmt.Errorf("unknown event sink %q, expected stdout, file or webhook", sink)
+	}
+}
+
+type writerPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterPublisher writes each message as a line of JSON
+func NewWriterPublisher(w io.Writer) Publisher {
+	return &writerPublisher{w: w}
+}
+
+func (p *writerPublisher) Publish(ctx context.Context, message Message) error {
+	line, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
+
+// NewFilePublisher appends each message as a line of JSON to the file at path
+func NewFilePublisher(path string) (Publisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterPublisher(file), nil
+}
+
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher posts each message to url. The message ID is sent as the Idempotency-Key header.
+func NewWebhookPublisher(url string) Publisher {
+	return &webhookPublisher{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (p *webhookPublisher) Publish(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", message.ID)
+	req.Header.Set("X-Event-Type", message.Type)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type multiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher publishes each message to every publisher in turn and stops at the first error.
+// A message is retried on all of them, so they must tolerate duplicates.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return &multiPublisher{publishers: publishers}
+}
+
+func (p *multiPublisher) Publish(ctx context.Context, message Message) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
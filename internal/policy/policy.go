@@ -0,0 +1,230 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Effect is the outcome a policy produces when it matches
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// Document is the content of a policy file
+type Document struct {
+	Policies []Policy `json:"policies"`
+}
+
+// Policy grants or denies actions on resources to subjects that satisfy all conditions
+type Policy struct {
+	ID          string      `json:"id"`
+	Description string      `json:"description"`
+	Effect      Effect      `json:"effect"`
+	Actions     []string    `json:"actions"`   // e.g. "orders:read", "orders:*" or "*"
+	Resources   []string    `json:"resources"` // resource types, e.g. "order" or "*"
+	Subjects    Subjects    `json:"subjects"`
+	Conditions  []Condition `json:"conditions"`
+}
+
+// Subjects restricts a policy to subjects holding any of the roles and all of the permissions.
+// Empty lists match every subject.
+type Subjects struct {
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// Condition compares an attribute of the subject or resource, such as "resource.store_id",
+// with a literal value, a list of values, or another attribute given in ValueFrom.
+type Condition struct {
+	Attribute string   `json:"attribute"`
+	Operator  string   `json:"operator"` // equals, not_equals, in, exists or not_exists
+	Value     string   `json:"value,omitempty"`
+	Values    []string `json:"values,omitempty"`
+	ValueFrom string   `json:"value_from,omitempty"`
+}
+
+// Subject is the caller an authorization request is made for
+type Subject struct {
+	Roles       []string
+	Permissions []string
+	Attributes  map[string]string // e.g. user_id, role
+}
+
+// Request asks whether the subject may perform the action on the resource
+type Request struct {
+	Subject    Subject
+	Action     string
+	Resource   string
+	Attributes map[string]string // attributes of the resource, e.g. owner_id, store_id
+}
+
+// Decision is the result of evaluating a request
+type Decision struct {
+	Allowed  bool
+	Reason   string
+	PolicyID string
+}
+
+// Validate checks that the document only uses known effects and operators
+func (d *Document) Validate() error {
+	seen := make(map[string]bool, len(d.Policies))
+	for i, p := range d.Policies {
+		if p.ID == "" {
+			return fmt.Errorf("policy %d: id is required", i)
+		}
+		if seen[p.ID] {
+			return fmt.Errorf("policy %q: duplicate id", p.ID)
+		}
+		seen[p.ID] = true
+
+		if p.Effect != Allow && p.Effect != Deny {
+			return fmt.Errorf("policy %q: effect must be allow or deny", p.ID)
+		}
+		if len(p.Actions) == 0 || len(p.Resources) == 0 {
+			return fmt.Errorf("policy %q: actions and resources are required", p.ID)
+		}
+
+		for _, c := range p.Conditions {
+			if !strings.HasPrefix(c.Attribute, "subject.") && !strings.HasPrefix(c.Attribute, "resource.") {
+				return fmt.Errorf("policy %q: attribute %q must start with subject. or resource.", p.ID, c.Attribute)
+			}
+			switch c.Operator {
+			case "equals", "not_equals", "in", "exists", "not_exists":
+			default:
+				return fmt.Errorf("policy %q: unknown operator %q", p.ID, c.Operator)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate applies the policies to the request. A matching deny policy always wins,
+// otherwise the first matching allow policy grants access. Requests no policy matches are denied.
+func (d *Document) Evaluate(req Request) Decision {
+	var allowedBy *Policy
+	for i := range d.Policies {
+		p := &d.Policies[i]
+		if !p.matches(req) {
+			continue
+		}
+		if p.Effect == Deny {
+			return Decision{Allowed: false, Reason: reason("Denied", p), PolicyID: p.ID}
+		}
+		if allowedBy == nil {
+			allowedBy = p
+		}
+	}
+
+	if allowedBy != nil {
+		return Decision{Allowed: true, Reason: reason("Allowed", allowedBy), PolicyID: allowedBy.ID}
+	}
+	return Decision{Allowed: false, Reason: "No policy allows this action"}
+}
+
+func reason(prefix string, p *Policy) string {
+	if p.Description != "" {
+		return fmt.Sprintf("%s by policy %s: %s", prefix, p.ID, p.Description)
+	}
+	return fmt.Sprintf("%s by policy %s", prefix, p.ID)
+}
+
+func (p *Policy) matches(req Request) bool {
+	if !matchAny(p.Actions, req.Action) || !matchAny(p.Resources, req.Resource) {
+		return false
+	}
+
+	if len(p.Subjects.Roles) > 0 && !intersects(p.Subjects.Roles, req.Subject.Roles) {
+		return false
+	}
+	for _, permission := range p.Subjects.Permissions {
+		if !contains(req.Subject.Permissions, permission) {
+			return false
+		}
+	}
+
+	for _, c := range p.Conditions {
+		if !c.holds(req) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) holds(req Request) bool {
+	value, ok := lookup(req, c.Attribute)
+
+	switch c.Operator {
+	case "exists":
+		return ok
+	case "not_exists":
+		return !ok
+	}
+
+	expected := c.Value
+	if c.ValueFrom != "" {
+		var found bool
+		expected, found = lookup(req, c.ValueFrom)
+		// Comparing with a missing attribute never matches
+		if !found {
+			return false
+		}
+	}
+
+	switch c.Operator {
+	case "equals":
+		return ok && value == expected
+	case "not_equals":
+		return !ok || value != expected
+	case "in":
+		return ok && contains(c.Values, value)
+	}
+	return false
+}
+
+// lookup resolves "subject.<name>" and "resource.<name>" attributes
+func lookup(req Request, attribute string) (string, bool) {
+	if name, ok := strings.CutPrefix(attribute, "subject."); ok {
+		value, found := req.Subject.Attributes[name]
+		return value, found && value != ""
+	}
+	if name, ok := strings.CutPrefix(attribute, "resource."); ok {
+		value, found := req.Attributes[name]
+		return value, found && value != ""
+	}
+	return "", false
+}
+
+// matchAny reports whether value matches one of the patterns.
+// "*" matches everything and a trailing "*" matches any suffix.
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(a, b []string) bool {
+	for _, v := range a {
+		if contains(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// loadPolicies reads the policy file shipped with the service
+func loadPolicies(t *testing.T) *Document {
+	t.Helper()
+	data, err := os.ReadFile("../../policies.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("policies.json is invalid: %v", err)
+	}
+	return &doc
+}
+
+func TestEvaluate(t *testing.T) {
+	doc := loadPolicies(t)
+
+	customer := Subject{
+		Roles:       []string{"customer"},
+		Permissions: []string{"orders:read_own"},
+		Attributes:  map[string]string{"user_id": "u1", "role": "customer"},
+	}
+	pharmacist := Subject{
+		Roles:       []string{"pharmacist"},
+		Permissions: []string{"prescriptions:read", "prescriptions:approve"},
+		Attributes:  map[string]string{"user_id": "p1", "role": "pharmacist", "store_id": "s1"},
+	}
+	admin := Subject{
+		Roles:      []string{"admin"},
+		Attributes: map[string]string{"user_id": "a1", "role": "admin"},
+	}
+
+	tests := []struct {
+		name       string
+		req        Request
+		wantAllow  bool
+		wantPolicy string
+	}{
+		{
+			name:       "customer reads own order",
+			req:        Request{Subject: customer, Action: "orders:read", Resource: "order", Attributes: map[string]string{"owner_id": "u1"}},
+			wantAllow:  true,
+			wantPolicy: "customer-read-own-orders",
+		},
+		{
+			name: "customer reads someone else's order",
+			req:  Request{Subject: customer, Action: "orders:read", Resource: "order", Attributes: map[string]string{"owner_id": "u2"}},
+		},
+		{
+			name: "customer reads order without owner",
+			req:  Request{Subject: customer, Action: "orders:read", Resource: "order"},
+		},
+		{
+			name: "customer reads order with empty owner",
+			req:  Request{Subject: customer, Action: "orders:read", Resource: "order", Attributes: map[string]string{"owner_id": ""}},
+		},
+		{
+			name: "customer action on other resource type",
+			req:  Request{Subject: customer, Action: "orders:read", Resource: "prescription", Attributes: map[string]string{"owner_id": "u1"}},
+		},
+		{
+			name:       "pharmacist approves in own store",
+			req:        Request{Subject: pharmacist, Action: "prescriptions:approve", Resource: "prescription", Attributes: map[string]string{"owner_id": "u1", "store_id": "s1"}},
+			wantAllow:  true,
+			wantPolicy: "pharmacist-approve-prescriptions-own-store",
+		},
+		{
+			name: "pharmacist approves in other store",
+			req:  Request{Subject: pharmacist, Action: "prescriptions:approve", Resource: "prescription", Attributes: map[string]string{"owner_id": "u1", "store_id": "s2"}},
+		},
+		{
+			name:       "pharmacist approves own prescription",
+			req:        Request{Subject: pharmacist, Action: "prescriptions:approve", Resource: "prescription", Attributes: map[string]string{"owner_id": "p1", "store_id": "s1"}},
+			wantPolicy: "no-self-approval",
+		},
+		{
+			name: "pharmacist without the permission",
+			req: Request{
+				Subject:    Subject{Roles: []string{"pharmacist"}, Attributes: map[string]string{"user_id": "p1", "store_id": "s1"}},
+				Action:     "prescriptions:approve",
+				Resource:   "prescription",
+				Attributes: map[string]string{"owner_id": "u1", "store_id": "s1"},
+			},
+		},
+		{
+			name:       "admin does anything",
+			req:        Request{Subject: admin, Action: "orders:refund", Resource: "order"},
+			wantAllow:  true,
+			wantPolicy: "admin-full-access",
+		},
+		{
+			name:       "deny wins over admin allow",
+			req:        Request{Subject: admin, Action: "prescriptions:approve", Resource: "prescription", Attributes: map[string]string{"owner_id": "a1"}},
+			wantPolicy: "no-self-approval",
+		},
+		{
+			name: "no roles",
+			req:  Request{Subject: Subject{}, Action: "orders:read", Resource: "order"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := doc.Evaluate(tt.req)
+			if got.Allowed != tt.wantAllow {
+				t.Fatalf("Evaluate() allowed = %v, want %v (%s)", got.Allowed, tt.wantAllow, got.Reason)
+			}
+			if got.PolicyID != tt.wantPolicy {
+				t.Fatalf("Evaluate() policy = %q, want %q", got.PolicyID, tt.wantPolicy)
+			}
+			if got.Reason == "" {
+				t.Fatal("Evaluate() gave no reason")
+			}
+		})
+	}
+}
+
+func TestConditionOperators(t *testing.T) {
+	req := Request{
+		Subject:    Subject{Attributes: map[string]string{"user_id": "u1"}},
+		Attributes: map[string]string{"status": "pending", "owner_id": "u1"},
+	}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{name: "equals value", condition: Condition{Attribute: "resource.status", Operator: "equals", Value: "pending"}, want: true},
+		{name: "equals other value", condition: Condition{Attribute: "resource.status", Operator: "equals", Value: "shipped"}},
+		{name: "equals missing attribute", condition: Condition{Attribute: "resource.store_id", Operator: "equals", Value: ""}},
+		{name: "equals attribute", condition: Condition{Attribute: "resource.owner_id", Operator: "equals", ValueFrom: "subject.user_id"}, want: true},
+		{name: "equals missing value_from", condition: Condition{Attribute: "resource.owner_id", Operator: "equals", ValueFrom: "subject.store_id"}},
+		{name: "not_equals value", condition: Condition{Attribute: "resource.status", Operator: "not_equals", Value: "shipped"}, want: true},
+		{name: "not_equals same value", condition: Condition{Attribute: "resource.status", Operator: "not_equals", Value: "pending"}},
+		{name: "not_equals missing attribute", condition: Condition{Attribute: "resource.store_id", Operator: "not_equals", Value: "s1"}, want: true},
+		{name: "not_equals missing value_from", condition: Condition{Attribute: "resource.owner_id", Operator: "not_equals", ValueFrom: "subject.store_id"}},
+		{name: "in listed", condition: Condition{Attribute: "resource.status", Operator: "in", Values: []string{"pending", "approved"}}, want: true},
+		{name: "in not listed", condition: Condition{Attribute: "resource.status", Operator: "in", Values: []string{"approved"}}},
+		{name: "in missing attribute", condition: Condition{Attribute: "resource.store_id", Operator: "in", Values: []string{""}}},
+		{name: "exists", condition: Condition{Attribute: "resource.status", Operator: "exists"}, want: true},
+		{name: "exists missing", condition: Condition{Attribute: "resource.store_id", Operator: "exists"}},
+		{name: "not_exists", condition: Condition{Attribute: "resource.store_id", Operator: "not_exists"}, want: true},
+		{name: "not_exists present", condition: Condition{Attribute: "subject.user_id", Operator: "not_exists"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.holds(req); got != tt.want {
+				t.Fatalf("holds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		value    string
+		want     bool
+	}{
+		{patterns: []string{"*"}, value: "orders:read", want: true},
+		{patterns: []string{"orders:*"}, value: "orders:read", want: true},
+		{patterns: []string{"orders:*"}, value: "prescriptions:read"},
+		{patterns: []string{"orders:read"}, value: "orders:read", want: true},
+		{patterns: []string{"orders:read"}, value: "orders:read_own"},
+		{patterns: []string{"orders:read", "prescriptions:read"}, value: "prescriptions:read", want: true},
+		{patterns: nil, value: "orders:read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.patterns, ",")+" "+tt.value, func(t *testing.T) {
+			if got := matchAny(tt.patterns, tt.value); got != tt.want {
+				t.Fatalf("matchAny() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Policy{ID: "p", Effect: Allow, Actions: []string{"*"}, Resources: []string{"*"}}
+
+	tests := []struct {
+		name    string
+		modify  func(p *Policy)
+		twice   bool
+		wantErr string
+	}{
+		{name: "valid", modify: func(p *Policy) {}},
+		{name: "missing id", modify: func(p *Policy) { p.ID = "" }, wantErr: "id is required"},
+		{name: "duplicate id", modify: func(p *Policy) {}, twice: true, wantErr: "duplicate id"},
+		{name: "unknown effect", modify: func(p *Policy) { p.Effect = "maybe" }, wantErr: "effect must be allow or deny"},
+		{name: "no actions", modify: func(p *Policy) { p.Actions = nil }, wantErr: "actions and resources are required"},
+		{name: "no resources", modify: func(p *Policy) { p.Resources = nil }, wantErr: "actions and resources are required"},
+		{
+			name:    "attribute without scope",
+			modify:  func(p *Policy) { p.Conditions = []Condition{{Attribute: "owner_id", Operator: "exists"}} },
+			wantErr: "must start with subject. or resource.",
+		},
+		{
+			name:    "unknown operator",
+			modify:  func(p *Policy) { p.Conditions = []Condition{{Attribute: "resource.owner_id", Operator: "contains"}} },
+			wantErr: "unknown operator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+			doc := Document{Policies: []Policy{p}}
+			if tt.twice {
+				doc.Policies = append(doc.Policies, p)
+			}
+
+			err := doc.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateWithoutPolicies(t *testing.T) {
+	got := (&Document{}).Evaluate(Request{Subject: Subject{Roles: []string{"admin"}}, Action: "orders:read", Resource: "order"})
+	if got.Allowed {
+		t.Fatal("a request no policy matches was allowed")
+	}
+}
@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+)
+
+// Store holds the policies loaded from a file and reloads them when the file changes
+type Store struct {
+	path    string
+	mu      sync.RWMutex
+	doc     *Document
+	modTime time.Time
+}
+
+// NewStore loads the policies from path. A missing file yields an empty store that denies every request.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, doc: &Document{}}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		utils.Warn("Policy file not found, all authorization requests will be denied", map[string]interface{}{
+			"path": path,
+		})
+		return s, nil
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Evaluate applies the current policies to the request
+func (s *Store) Evaluate(req Request) Decision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.Evaluate(req)
+}
+
+// Reload reads and validates the policy file, replacing the current policies on success
+func (s *Store) Reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.doc = &doc
+	s.modTime = info.ModTime()
+	s.mu.Unlock()
+
+	utils.Info("Policies loaded", map[string]interface{}{
+		"path":     s.path,
+		"policies": len(doc.Policies),
+	})
+	return nil
+}
+
+// Watch reloads the policies whenever the file's modification time changes, until ctx is done.
+// An invalid file is logged and the previous policies stay in effect.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(s.path)
+			if err != nil {
+				continue
+			}
+
+			s.mu.RLock()
+			changed := !info.ModTime().Equal(s.modTime)
+			s.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err := s.Reload(); err != nil {
+				utils.Error("Failed to reload policies", map[string]interface{}{
+					"path":  s.path,
+					"error": err.Error(),
+				})
+				// Do not retry the same broken file on every tick
+				s.mu.Lock()
+				s.modTime = info.ModTime()
+				s.mu.Unlock()
+			}
+		}
+	}
+}
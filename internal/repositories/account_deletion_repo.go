@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type AccountDeletionRepository interface {
+	CreateDeletion(deletion *models.AccountDeletion) error
+	GetPendingDeletion(userID string) (*models.AccountDeletion, error)
+	GetDeletionsByUserID(userID string) ([]models.AccountDeletion, error)
+	GetDueDeletions(now time.Time) ([]models.AccountDeletion, error)
+	UpdateDeletion(deletion *models.AccountDeletion) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) AccountDeletionRepository
+}
+
+type accountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) AccountDeletionRepository {
+	return &accountDeletionRepository{db}
+}
+
+func (r *accountDeletionRepository) WithContext(ctx context.Context) AccountDeletionRepository {
+	return &accountDeletionRepository{r.db.WithContext(ctx)}
+}
+
+func (r *accountDeletionRepository) CreateDeletion(deletion *models.AccountDeletion) error {
+	return r.db.Create(deletion).Error
+}
+
+// GetPendingDeletion returns the user's deletion request that is neither cancelled nor completed
+func (r *accountDeletionRepository) GetPendingDeletion(userID string) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := r.db.Where("user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL", userID).First(&deletion).Error
+	return &deletion, err
+}
+
+func (r *accountDeletionRepository) GetDeletionsByUserID(userID string) ([]models.AccountDeletion, error) {
+	var deletions []models.AccountDeletion
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&deletions).Error
+	return deletions, err
+}
+
+// GetDueDeletions returns the pending deletion requests whose grace period has ended
+func (r *accountDeletionRepository) GetDueDeletions(now time.Time) ([]models.AccountDeletion, error) {
+	var deletions []models.AccountDeletion
+	err := r.db.
+		Where("cancelled_at IS NULL AND completed_at IS NULL AND scheduled_for <= ?", now).
+		Order("scheduled_for ASC").
+		Find(&deletions).Error
+	return deletions, err
+}
+
+func (r *accountDeletionRepository) UpdateDeletion(deletion *models.AccountDeletion) error {
+	return r.db.Save(deletion).Error
+}
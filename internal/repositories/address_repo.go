@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type AddressRepository interface {
+	CreateAddress(address *models.Address) error
+	GetAddressByID(id string) (*models.Address, error)
+	GetAddressesByCustomerID(customerID string) ([]models.Address, error)
+	GetDefaultBillingAddress(customerID string) (*models.Address, error)
+	UpdateAddress(address *models.Address) error
+	DeleteAddress(address *models.Address) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) AddressRepository
+}
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepository{db}
+}
+
+func (r *addressRepository) WithContext(ctx context.Context) AddressRepository {
+	return &addressRepository{r.db.WithContext(ctx)}
+}
+
+// CreateAddress adds the address, taking the default flags over from the customer's other addresses when set
+func (r *addressRepository) CreateAddress(address *models.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaults(tx, address); err != nil {
+			return err
+		}
+		return tx.Create(address).Error
+	})
+}
+
+func (r *addressRepository) GetAddressByID(id string) (*models.Address, error) {
+	var address models.Address
+	err := r.db.Where("id = ?", id).First(&address).Error
+	return &address, err
+}
+
+func (r *addressRepository) GetAddressesByCustomerID(customerID string) ([]models.Address, error) {
+	var addresses []models.Address
+	err := r.db.Where("customer_id = ?", customerID).Order("created_at ASC, id ASC").Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *addressRepository) GetDefaultBillingAddress(customerID string) (*models.Address, error) {
+	var address models.Address
+	err := r.db.Where("customer_id = ? AND is_default_billing", customerID).First(&address).Error
+	return &address, err
+}
+
+// UpdateAddress saves the address, taking the default flags over from the customer's other addresses when set
+func (r *addressRepository) UpdateAddress(address *models.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaults(tx, address); err != nil {
+			return err
+		}
+		return tx.Save(address).Error
+	})
+}
+
+// DeleteAddress removes the address. Its default flags pass to the customer's oldest remaining address.
+func (r *addressRepository) DeleteAddress(address *models.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(address).Error; err != nil {
+			return err
+		}
+
+		if !address.IsDefaultShipping && !address.IsDefaultBilling {
+			return nil
+		}
+
+		var next models.Address
+		err := tx.Where("customer_id = ?", address.CustomerID).Order("created_at ASC, id ASC").First(&next).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if address.IsDefaultShipping {
+			updates["is_default_shipping"] = true
+		}
+		if address.IsDefaultBilling {
+			updates["is_default_billing"] = true
+		}
+		return tx.Model(&next).Updates(updates).Error
+	})
+}
+
+// clearDefaults unsets the default flags that the address claims on the customer's other addresses
+func clearDefaults(tx *gorm.DB, address *models.Address) error {
+	if address.IsDefaultShipping {
+		err := tx.Model(&models.Address{}).
+			Where("customer_id = ? AND id <> ? AND is_default_shipping", address.CustomerID, address.ID).
+			Update("is_default_shipping", false).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if address.IsDefaultBilling {
+		err := tx.Model(&models.Address{}).
+			Where("customer_id = ? AND id <> ? AND is_default_billing", address.CustomerID, address.ID).
+			Update("is_default_billing", false).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+// auditChainLock is the advisory lock key serializing appends to the audit chain
+const auditChainLock = 4117270301
+
+// AuditQuery describes one page of audit events, newest first.
+// When BeforeSequence is set the page starts after the event with that sequence.
+type AuditQuery struct {
+	Action         string
+	ActorID        string
+	SubjectID      string
+	From           *time.Time
+	To             *time.Time
+	BeforeSequence int64
+	Limit          int
+}
+
+type AuditRepository interface {
+	AppendEvent(event *models.AuditEvent) error
+	QueryEvents(query AuditQuery) ([]models.AuditEvent, error)
+	GetEventsAfter(sequence int64, limit int) ([]models.AuditEvent, error)
+	GetEventsByUserID(userID string) ([]models.AuditEvent, error)
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) AuditRepository
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db}
+}
+
+func (r *auditRepository) WithContext(ctx context.Context) AuditRepository {
+	return &auditRepository{r.db.WithContext(ctx)}
+}
+
+// AppendEvent adds the event to the end of the chain, setting its sequence, hashes and creation time
+func (r *auditRepository) AppendEvent(event *models.AuditEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Appends are serialized so that every event links to the one before it
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLock).Error; err != nil {
+			return err
+		}
+
+		var last models.AuditEvent
+		err := tx.Where("sequence IS NOT NULL").Order("sequence DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		event.Sequence = last.Sequence + 1
+		event.PrevHash = last.Hash
+		if event.Sequence == 1 {
+			event.PrevHash = models.AuditGenesisHash
+		}
+		if event.Details == "" {
+			event.Details = "{}"
+		}
+		// The database keeps microseconds, the hash has to match what is read back
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+		hash, err := event.ComputeHash()
+		if err != nil {
+			return err
+		}
+		event.Hash = hash
+
+		return tx.Create(event).Error
+	})
+}
+
+// QueryEvents returns the events matching the query, newest first
+func (r *auditRepository) QueryEvents(query AuditQuery) ([]models.AuditEvent, error) {
+	tx := r.db.Where("sequence IS NOT NULL")
+	if query.Action != "" {
+		tx = tx.Where("action = ?", query.Action)
+	}
+	if query.ActorID != "" {
+		tx = tx.Where("actor_id = ?", query.ActorID)
+	}
+	if query.SubjectID != "" {
+		tx = tx.Where("subject_id = ?", query.SubjectID)
+	}
+	if query.From != nil {
+		tx = tx.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		tx = tx.Where("created_at < ?", *query.To)
+	}
+	if query.BeforeSequence > 0 {
+		tx = tx.Where("sequence < ?", query.BeforeSequence)
+	}
+
+	var events []models.AuditEvent
+	err := tx.Order("sequence DESC").Limit(query.Limit).Find(&events).Error
+	return events, err
+}
+
+// GetEventsAfter returns up to limit events following the given sequence, in chain order
+func (r *auditRepository) GetEventsAfter(sequence int64, limit int) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	err := r.db.Where("sequence > ?", sequence).Order("sequence ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// GetEventsByUserID returns the events the user performed or was the subject of, oldest first
+func (r *auditRepository) GetEventsByUserID(userID string) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	err := r.db.Where("actor_id = ? OR subject_id = ?", userID, userID).Order("created_at ASC").Find(&events).Error
+	return events, err
+}
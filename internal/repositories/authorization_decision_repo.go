@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuthorizationDecisionRepository interface {
+	CreateDecision(decision *models.AuthorizationDecision) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) AuthorizationDecisionRepository
+}
+
+type authorizationDecisionRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthorizationDecisionRepository(db *gorm.DB) AuthorizationDecisionRepository {
+	return &authorizationDecisionRepository{db}
+}
+
+func (r *authorizationDecisionRepository) WithContext(ctx context.Context) AuthorizationDecisionRepository {
+	return &authorizationDecisionRepository{r.db.WithContext(ctx)}
+}
+
+func (r *authorizationDecisionRepository) CreateDecision(decision *models.AuthorizationDecision) error {
+	return r.db.Create(decision).Error
+}
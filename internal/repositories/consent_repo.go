@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type ConsentRepository interface {
+	SeedPolicies(policies []models.PolicyDocument) error
+	CreatePolicy(policy *models.PolicyDocument) error
+	GetPolicy(policy, version string) (*models.PolicyDocument, error)
+	GetCurrentPolicies(now time.Time) ([]models.PolicyDocument, error)
+	CreateConsents(consents []models.Consent) error
+	GetConsentsByUserID(userID string) ([]models.Consent, error)
+	GetLatestConsents(userID string) ([]models.Consent, error)
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) ConsentRepository
+}
+
+type consentRepository struct {
+	db *gorm.DB
+}
+
+func NewConsentRepository(db *gorm.DB) ConsentRepository {
+	return &consentRepository{db}
+}
+
+func (r *consentRepository) WithContext(ctx context.Context) ConsentRepository {
+	return &consentRepository{r.db.WithContext(ctx)}
+}
+
+// SeedPolicies creates the given policies that have no version yet, in effect from now
+func (r *consentRepository) SeedPolicies(policies []models.PolicyDocument) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, policy := range policies {
+			var count int64
+			if err := tx.Model(&models.PolicyDocument{}).Where("policy = ?", policy.Policy).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			policy.EffectiveAt = time.Now()
+			if err := tx.Create(&policy).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *consentRepository) CreatePolicy(policy *models.PolicyDocument) error {
+	return r.db.Create(policy).Error
+}
+
+func (r *consentRepository) GetPolicy(policy, version string) (*models.PolicyDocument, error) {
+	var document models.PolicyDocument
+	err := r.db.Where("policy = ? AND version = ?", policy, version).First(&document).Error
+	return &document, err
+}
+
+// GetCurrentPolicies returns the latest version in effect of each policy
+func (r *consentRepository) GetCurrentPolicies(now time.Time) ([]models.PolicyDocument, error) {
+	var policies []models.PolicyDocument
+	err := r.db.
+		Raw(`SELECT DISTINCT ON (policy) * FROM policy_documents
+			WHERE effective_at <= ? ORDER BY policy, effective_at DESC, created_at DESC`, now).
+		Scan(&policies).Error
+	return policies, err
+}
+
+func (r *consentRepository) CreateConsents(consents []models.Consent) error {
+	if len(consents) == 0 {
+		return nil
+	}
+	return r.db.Create(&consents).Error
+}
+
+// GetConsentsByUserID returns the user's ledger entries, newest first
+func (r *consentRepository) GetConsentsByUserID(userID string) ([]models.Consent, error) {
+	var consents []models.Consent
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&consents).Error
+	return consents, err
+}
+
+// GetLatestConsents returns the user's most recent entry for each policy
+func (r *consentRepository) GetLatestConsents(userID string) ([]models.Consent, error) {
+	var consents []models.Consent
+	err := r.db.
+		Raw(`SELECT DISTINCT ON (policy) * FROM consents
+			WHERE user_id = ? ORDER BY policy, created_at DESC`, userID).
+		Scan(&consents).Error
+	return consents, err
+}
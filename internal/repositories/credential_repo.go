@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CredentialRepository interface {
+	CreateCredential(credential *models.ProfessionalCredential) error
+	GetCredentialByID(id string) (*models.ProfessionalCredential, error)
+	GetCredentialsByUserID(userID string) ([]models.ProfessionalCredential, error)
+	ListCredentials(status string, limit, offset int) ([]models.ProfessionalCredential, error)
+	UpdateCredential(credential *models.ProfessionalCredential) error
+	GetLicensedProvinces(userID string, today time.Time) ([]string, *time.Time, error)
+	FlagExpiring(before time.Time) ([]models.ProfessionalCredential, error)
+	ExpireCredentials(today time.Time) ([]models.ProfessionalCredential, error)
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) CredentialRepository
+}
+
+type credentialRepository struct {
+	db *gorm.DB
+}
+
+func NewCredentialRepository(db *gorm.DB) CredentialRepository {
+	return &credentialRepository{db}
+}
+
+func (r *credentialRepository) WithContext(ctx context.Context) CredentialRepository {
+	return &credentialRepository{r.db.WithContext(ctx)}
+}
+
+func (r *credentialRepository) CreateCredential(credential *models.ProfessionalCredential) error {
+	return r.db.Create(credential).Error
+}
+
+func (r *credentialRepository) GetCredentialByID(id string) (*models.ProfessionalCredential, error) {
+	var credential models.ProfessionalCredential
+	err := r.db.Where("id = ?", id).First(&credential).Error
+	return &credential, err
+}
+
+func (r *credentialRepository) GetCredentialsByUserID(userID string) ([]models.ProfessionalCredential, error) {
+	var credentials []models.ProfessionalCredential
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&credentials).Error
+	return credentials, err
+}
+
+func (r *credentialRepository) ListCredentials(status string, limit, offset int) ([]models.ProfessionalCredential, error) {
+	tx := r.db
+	if status != "" {
+		tx = tx.Where("status = ?", status)
+	}
+
+	var credentials []models.ProfessionalCredential
+	err := tx.Order("created_at ASC, id ASC").Limit(limit).Offset(offset).Find(&credentials).Error
+	return credentials, err
+}
+
+func (r *credentialRepository) UpdateCredential(credential *models.ProfessionalCredential) error {
+	return r.db.Save(credential).Error
+}
+
+// GetLicensedProvinces returns the provinces the user holds a verified, unexpired license in,
+// and the last day of validity of the first of them to lapse, or nil when there are none
+func (r *credentialRepository) GetLicensedProvinces(userID string, today time.Time) ([]string, *time.Time, error) {
+	var licenses []struct {
+		IssuingProvince string
+		ExpiresOn       time.Time
+	}
+	err := r.db.Model(&models.ProfessionalCredential{}).
+		Select("issuing_province, MAX(expires_on) AS expires_on").
+		Where("user_id = ? AND status = ? AND expires_on >= ?", userID, models.CredentialVerified, today.Format("2006-01-02")).
+		Group("issuing_province").
+		Order("issuing_province ASC").
+		Scan(&licenses).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provinces := make([]string, 0, len(licenses))
+	var lastDay *time.Time
+	for _, license := range licenses {
+		provinces = append(provinces, license.IssuingProvince)
+		if lastDay == nil || license.ExpiresOn.Before(*lastDay) {
+			expiresOn := license.ExpiresOn
+			lastDay = &expiresOn
+		}
+	}
+	return provinces, lastDay, nil
+}
+
+// FlagExpiring marks verified credentials expiring before the given day that have not been flagged yet
+// and returns them
+func (r *credentialRepository) FlagExpiring(before time.Time) ([]models.ProfessionalCredential, error) {
+	var credentials []models.ProfessionalCredential
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status = ? AND expiry_flagged_at IS NULL AND expires_on < ?", models.CredentialVerified, before.Format("2006-01-02")).
+			Find(&credentials).Error; err != nil {
+			return err
+		}
+		if len(credentials) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(credentials))
+		for _, credential := range credentials {
+			ids = append(ids, credential.ID.String())
+		}
+		return tx.Model(&models.ProfessionalCredential{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"expiry_flagged_at": time.Now(), "updated_at": time.Now()}).Error
+	})
+	return credentials, err
+}
+
+// ExpireCredentials downgrades verified credentials whose expiry date has passed and returns them
+func (r *credentialRepository) ExpireCredentials(today time.Time) ([]models.ProfessionalCredential, error) {
+	var credentials []models.ProfessionalCredential
+	err := r.db.Model(&credentials).
+		Clauses(clause.Returning{}).
+		Where("status = ? AND expires_on < ?", models.CredentialVerified, today.Format("2006-01-02")).
+		Updates(map[string]interface{}{"status": models.CredentialExpired, "updated_at": time.Now()}).Error
+	return credentials, err
+}
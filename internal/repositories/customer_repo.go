@@ -1,14 +1,21 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"github.com/PharmaKart/authentication-svc/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type CustomerRepository interface {
-	CreateCustomer(customer *models.Customer) (uuid.UUID, error)
+	CreateCustomer(customer *models.Customer, events ...*models.OutboxEvent) (uuid.UUID, error)
+	RegisterCustomer(user *models.User, roleID uuid.UUID, customer *models.Customer, consents []models.Consent, events ...*models.OutboxEvent) (uuid.UUID, error)
 	GetCustomerByUserID(userID string) (*models.Customer, error)
+	UpdateCustomerFields(id uuid.UUID, fields map[string]interface{}, version int, events ...*models.OutboxEvent) (bool, error)
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) CustomerRepository
 }
 
 type customerRepository struct {
@@ -19,15 +26,86 @@ func NewCustomerRepository(db *gorm.DB) CustomerRepository {
 	return &customerRepository{db}
 }
 
-func (r *customerRepository) CreateCustomer(customer *models.Customer) (uuid.UUID, error) {
-	if err := r.db.Create(customer).Error; err != nil {
+func (r *customerRepository) WithContext(ctx context.Context) CustomerRepository {
+	return &customerRepository{r.db.WithContext(ctx)}
+}
+
+func (r *customerRepository) CreateCustomer(customer *models.Customer, events ...*models.OutboxEvent) (uuid.UUID, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(customer).Error; err != nil {
+			return err
+		}
+		return createOutboxEvents(tx, events)
+	})
+	if err != nil {
 		return uuid.Nil, err
 	}
 	return customer.ID, nil
 }
 
+// RegisterCustomer adds the user with the role, the customer record, the accepted consents and the events about them
+// in one transaction, so that a failed registration leaves nothing behind. The ID of the new user is set on the
+// customer, consents and events, and returned.
+func (r *customerRepository) RegisterCustomer(user *models.User, roleID uuid.UUID, customer *models.Customer, consents []models.Consent, events ...*models.OutboxEvent) (uuid.UUID, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+
+		customer.UserID = user.ID
+		if err := tx.Create(customer).Error; err != nil {
+			return err
+		}
+
+		for i := range consents {
+			consents[i].UserID = user.ID
+		}
+		if len(consents) > 0 {
+			if err := tx.Create(&consents).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, event := range events {
+			event.UserID = user.ID
+		}
+		return createOutboxEvents(tx, events)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return user.ID, nil
+}
+
 func (r *customerRepository) GetCustomerByUserID(userID string) (*models.Customer, error) {
 	var customer models.Customer
 	err := r.db.Where("user_id = ?", userID).First(&customer).Error
 	return &customer, err
 }
+
+// UpdateCustomerFields updates the given columns only if the customer is still at version,
+// and bumps the version. It reports false when another update got there first, in which case the events are not added.
+func (r *customerRepository) UpdateCustomerFields(id uuid.UUID, fields map[string]interface{}, version int, events ...*models.OutboxEvent) (bool, error) {
+	updates := make(map[string]interface{}, len(fields)+2)
+	for column, value := range fields {
+		updates[column] = value
+	}
+	updates["version"] = gorm.Expr("version + 1")
+	updates["updated_at"] = time.Now()
+
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Customer{}).
+			Where("id = ? AND version = ?", id, version).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		updated = true
+		return createOutboxEvents(tx, events)
+	})
+	return updated, err
+}
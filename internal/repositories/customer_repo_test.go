@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestUpdateCustomerFieldsVersion(t *testing.T) {
+	db := testDB(t)
+	repo := NewCustomerRepository(db)
+
+	user := models.User{Username: "customer-" + uuid.NewString()[:8], Email: uuid.NewString() + "@example.com", PasswordHash: "x", Role: "customer"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	customer := models.Customer{UserID: user.ID, FirstName: "Jane", LastName: "Doe"}
+	if _, err := repo.CreateCustomer(&customer); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each update is made against the version the caller read
+	tests := []struct {
+		name        string
+		firstName   string
+		version     int
+		wantUpdated bool
+		wantName    string
+		wantVersion int
+	}{
+		{name: "current version", firstName: "Janet", version: 1, wantUpdated: true, wantName: "Janet", wantVersion: 2},
+		{name: "stale version", firstName: "Jo", version: 1, wantName: "Janet", wantVersion: 2},
+		{name: "future version", firstName: "Jo", version: 3, wantName: "Janet", wantVersion: 2},
+		{name: "next version", firstName: "Joan", version: 2, wantUpdated: true, wantName: "Joan", wantVersion: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := models.NewOutboxEvent(models.EventUserUpdated, user.ID, map[string]interface{}{"first_name": tt.firstName})
+			updated, err := repo.UpdateCustomerFields(customer.ID, map[string]interface{}{"first_name": tt.firstName}, tt.version, event)
+			if err != nil {
+				t.Fatalf("UpdateCustomerFields() error = %v", err)
+			}
+			if updated != tt.wantUpdated {
+				t.Fatalf("UpdateCustomerFields() = %v, want %v", updated, tt.wantUpdated)
+			}
+
+			stored, err := repo.GetCustomerByUserID(user.ID.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.FirstName != tt.wantName || stored.Version != tt.wantVersion {
+				t.Fatalf("customer = %s at version %d, want %s at version %d", stored.FirstName, stored.Version, tt.wantName, tt.wantVersion)
+			}
+
+			// Rejected updates must not publish their event
+			var events int64
+			if err := db.Model(&models.OutboxEvent{}).Where("id = ?", event.ID).Count(&events).Error; err != nil {
+				t.Fatal(err)
+			}
+			if (events == 1) != tt.wantUpdated {
+				t.Fatalf("%d outbox events stored for the update, updated = %v", events, tt.wantUpdated)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type DataExportRepository interface {
+	CreateExport(export *models.DataExport) error
+	GetExportByID(id string) (*models.DataExport, error)
+	GetExportByTokenHash(tokenHash string) (*models.DataExport, error)
+	GetActiveExport(userID string) (*models.DataExport, error)
+	GetPendingExports() ([]models.DataExport, error)
+	ClaimExport(id string) (bool, error)
+	UpdateExport(export *models.DataExport) error
+	DeleteExpiredExports(now time.Time) (int64, error)
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) DataExportRepository
+}
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) DataExportRepository {
+	return &dataExportRepository{db}
+}
+
+func (r *dataExportRepository) WithContext(ctx context.Context) DataExportRepository {
+	return &dataExportRepository{r.db.WithContext(ctx)}
+}
+
+func (r *dataExportRepository) CreateExport(export *models.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+// GetExportByID returns the export without its document, which is only loaded for downloads
+func (r *dataExportRepository) GetExportByID(id string) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.Omit("data").Where("id = ?", id).First(&export).Error
+	return &export, err
+}
+
+func (r *dataExportRepository) GetExportByTokenHash(tokenHash string) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.Where("token_hash = ?", tokenHash).First(&export).Error
+	return &export, err
+}
+
+// GetActiveExport returns the user's export that is still being prepared
+func (r *dataExportRepository) GetActiveExport(userID string) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.Omit("data").
+		Where("user_id = ? AND status IN ?", userID, []string{models.ExportPending, models.ExportRunning}).
+		First(&export).Error
+	return &export, err
+}
+
+func (r *dataExportRepository) GetPendingExports() ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Omit("data").Where("status = ?", models.ExportPending).Order("created_at ASC").Find(&exports).Error
+	return exports, err
+}
+
+// ClaimExport marks a pending export as running. It reports false when another worker already claimed it.
+func (r *dataExportRepository) ClaimExport(id string) (bool, error) {
+	result := r.db.Model(&models.DataExport{}).
+		Where("id = ? AND status = ?", id, models.ExportPending).
+		Update("status", models.ExportRunning)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *dataExportRepository) UpdateExport(export *models.DataExport) error {
+	return r.db.Save(export).Error
+}
+
+// DeleteExpiredExports removes the exports whose download token has expired
+func (r *dataExportRepository) DeleteExpiredExports(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&models.DataExport{})
+	return result.RowsAffected, result.Error
+}
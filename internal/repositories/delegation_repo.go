@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type DelegationRepository interface {
+	CreateDependent(dependent *models.Dependent) error
+	GetDependentByID(id string) (*models.Dependent, error)
+	GetDependentsByOwnerID(ownerID string) ([]models.Dependent, error)
+	CreateGrant(grant *models.DelegationGrant) error
+	GetGrantByID(id string) (*models.DelegationGrant, error)
+	GetGrantsByUserID(userID string) ([]models.DelegationGrant, error)
+	GetActiveGrant(delegateID, subjectID string, now time.Time) (*models.DelegationGrant, error)
+	UpdateGrant(grant *models.DelegationGrant) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) DelegationRepository
+}
+
+type delegationRepository struct {
+	db *gorm.DB
+}
+
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &delegationRepository{db}
+}
+
+func (r *delegationRepository) WithContext(ctx context.Context) DelegationRepository {
+	return &delegationRepository{r.db.WithContext(ctx)}
+}
+
+func (r *delegationRepository) CreateDependent(dependent *models.Dependent) error {
+	return r.db.Create(dependent).Error
+}
+
+func (r *delegationRepository) GetDependentByID(id string) (*models.Dependent, error) {
+	var dependent models.Dependent
+	err := r.db.Where("id = ?", id).First(&dependent).Error
+	return &dependent, err
+}
+
+func (r *delegationRepository) GetDependentsByOwnerID(ownerID string) ([]models.Dependent, error) {
+	var dependents []models.Dependent
+	err := r.db.Where("owner_id = ?", ownerID).Order("created_at ASC").Find(&dependents).Error
+	return dependents, err
+}
+
+func (r *delegationRepository) CreateGrant(grant *models.DelegationGrant) error {
+	return r.db.Create(grant).Error
+}
+
+func (r *delegationRepository) GetGrantByID(id string) (*models.DelegationGrant, error) {
+	var grant models.DelegationGrant
+	err := r.db.Where("id = ?", id).First(&grant).Error
+	return &grant, err
+}
+
+// GetGrantsByUserID returns the grants the user has given, received or is the subject of
+func (r *delegationRepository) GetGrantsByUserID(userID string) ([]models.DelegationGrant, error) {
+	var grants []models.DelegationGrant
+	err := r.db.Where("delegate_id = ? OR granted_by = ? OR subject_id = ?", userID, userID, userID).
+		Order("created_at DESC").
+		Find(&grants).Error
+	return grants, err
+}
+
+// GetActiveGrant returns the most recent unrevoked, unexpired grant letting the delegate act for the subject
+func (r *delegationRepository) GetActiveGrant(delegateID, subjectID string, now time.Time) (*models.DelegationGrant, error) {
+	var grant models.DelegationGrant
+	err := r.db.
+		Where("delegate_id = ? AND subject_id = ?", delegateID, subjectID).
+		Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", now).
+		Order("created_at DESC").
+		First(&grant).Error
+	return &grant, err
+}
+
+func (r *delegationRepository) UpdateGrant(grant *models.DelegationGrant) error {
+	return r.db.Save(grant).Error
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	CreateInvitation(invitation *models.Invitation) error
+	GetInvitationByID(id string) (*models.Invitation, error)
+	GetInvitationByCodeHash(codeHash string) (*models.Invitation, error)
+	GetPendingInvitationByEmail(email string) (*models.Invitation, error)
+	ListInvitations(status string, limit, offset int) ([]models.Invitation, error)
+	UpdateInvitation(invitation *models.Invitation) error
+	AcceptInvitation(id string, user *models.User, roleID uuid.UUID, events ...*models.OutboxEvent) (bool, error)
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) InvitationRepository
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db}
+}
+
+func (r *invitationRepository) WithContext(ctx context.Context) InvitationRepository {
+	return &invitationRepository{r.db.WithContext(ctx)}
+}
+
+func (r *invitationRepository) CreateInvitation(invitation *models.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *invitationRepository) GetInvitationByID(id string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.Where("id = ?", id).First(&invitation).Error
+	return &invitation, err
+}
+
+func (r *invitationRepository) GetInvitationByCodeHash(codeHash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.Where("code_hash = ?", codeHash).First(&invitation).Error
+	return &invitation, err
+}
+
+func (r *invitationRepository) GetPendingInvitationByEmail(email string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, time.Now()).
+		First(&invitation).Error
+	return &invitation, err
+}
+
+// ListInvitations lists invitations newest first, optionally restricted to one status
+func (r *invitationRepository) ListInvitations(status string, limit, offset int) ([]models.Invitation, error) {
+	tx := r.db
+	now := time.Now()
+	switch status {
+	case "pending":
+		tx = tx.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case "accepted":
+		tx = tx.Where("accepted_at IS NOT NULL")
+	case "revoked":
+		tx = tx.Where("revoked_at IS NOT NULL")
+	case "expired":
+		tx = tx.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	}
+
+	var invitations []models.Invitation
+	err := tx.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) UpdateInvitation(invitation *models.Invitation) error {
+	return r.db.Save(invitation).Error
+}
+
+// AcceptInvitation creates the invited user with their role and the events about them, and marks the invitation
+// as accepted by them, in one transaction. It reports false, creating nothing, when the invitation was already used,
+// revoked or has expired, so each code can only be redeemed once.
+func (r *invitationRepository) AcceptInvitation(id string, user *models.User, roleID uuid.UUID, events ...*models.OutboxEvent) (bool, error) {
+	accepted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", id, now).
+			Update("accepted_at", now)
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Invitation{}).Where("id = ?", id).Update("accepted_user_id", user.ID).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			event.UserID = user.ID
+		}
+		if err := createOutboxEvents(tx, events); err != nil {
+			return err
+		}
+
+		accepted = true
+		return nil
+	})
+	return accepted, err
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	ClaimDueEvents(limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	UpdateEvent(event *models.OutboxEvent) error
+	DeletePublishedEvents(before time.Time) (int64, error)
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) OutboxRepository
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db}
+}
+
+func (r *outboxRepository) WithContext(ctx context.Context) OutboxRepository {
+	return &outboxRepository{r.db.WithContext(ctx)}
+}
+
+// ClaimDueEvents returns up to limit events due for publishing and pushes their next attempt back by lease,
+// so that other relays skip them meanwhile. Only the oldest pending event of each user is returned,
+// which keeps the events of a user in order.
+func (r *outboxRepository) ClaimDueEvents(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Raw(`
+			SELECT * FROM outbox_events e
+			WHERE e.published_at IS NULL AND e.failed_at IS NULL AND e.next_attempt_at <= ?
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_events p
+				WHERE p.user_id = e.user_id AND p.published_at IS NULL AND p.failed_at IS NULL
+				AND (p.created_at, p.id) < (e.created_at, e.id)
+			)
+			ORDER BY e.created_at ASC, e.id ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`, now, limit).Scan(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID.String())
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return events, err
+}
+
+func (r *outboxRepository) UpdateEvent(event *models.OutboxEvent) error {
+	return r.db.Save(event).Error
+}
+
+// DeletePublishedEvents removes the events published before the given time
+func (r *outboxRepository) DeletePublishedEvents(before time.Time) (int64, error) {
+	result := r.db.Where("published_at < ?", before).Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// createOutboxEvents writes events in the transaction of the change they describe
+func createOutboxEvents(tx *gorm.DB, events []*models.OutboxEvent) error {
+	for _, event := range events {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+// testDB returns a transaction on the database in TEST_DATABASE_URL that is rolled back when the test ends,
+// so tests see an existing database without changing it. Tests are skipped when the variable is not set.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrateOnce.Do(func() {
+		if migrateErr = db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; migrateErr == nil {
+			migrateErr = utils.MigrateDB(db)
+		}
+	})
+	if migrateErr != nil {
+		t.Fatal(migrateErr)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return tx
+}
@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	SeedDefaults() error
+	ListRoles() ([]models.Role, error)
+	GetRoleByName(name string) (*models.Role, error)
+	CreateRole(role *models.Role) error
+	UpdateRole(role *models.Role, permissions []models.Permission) error
+	DeleteRole(role *models.Role) error
+	CountUsersWithPrimaryRole(name string) (int64, error)
+	ListPermissions() ([]models.Permission, error)
+	GetPermissionsByName(names []string) ([]models.Permission, error)
+	GetUserRoles(userID string) ([]models.Role, error)
+	GetRoleNamesByUserIDs(userIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+	AssignRole(userID, roleID uuid.UUID) error
+	RevokeRole(userID, roleID uuid.UUID) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) RoleRepository
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) WithContext(ctx context.Context) RoleRepository {
+	return &roleRepository{r.db.WithContext(ctx)}
+}
+
+// SeedDefaults creates the default permissions and roles that do not exist yet,
+// grants every permission to the admin role and gives users without any role
+// their primary role from the users table.
+func (r *roleRepository) SeedDefaults() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for name, description := range models.DefaultPermissions {
+			permission := models.Permission{Name: name, Description: description}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+				return err
+			}
+		}
+
+		var permissions []models.Permission
+		if err := tx.Find(&permissions).Error; err != nil {
+			return err
+		}
+		byName := make(map[string]models.Permission, len(permissions))
+		for _, permission := range permissions {
+			byName[permission.Name] = permission
+		}
+
+		for name, permissionNames := range models.DefaultRoles {
+			role := models.Role{Name: name, System: true}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&role)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if name == "admin" {
+				// BeforeCreate set a new ID even when the insert was skipped, so load the stored role afresh
+				role = models.Role{}
+				if err := tx.Where("name = ?", name).First(&role).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&role).Association("Permissions").Append(permissions); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Only grant the default permissions to new roles so that changes made by admins are kept
+			if result.RowsAffected == 0 {
+				continue
+			}
+			rolePermissions := make([]models.Permission, 0, len(permissionNames))
+			for _, permissionName := range permissionNames {
+				rolePermissions = append(rolePermissions, byName[permissionName])
+			}
+			if err := tx.Model(&role).Association("Permissions").Append(rolePermissions); err != nil {
+				return err
+			}
+		}
+
+		// Users created before roles existed hold their primary role only
+		return tx.Exec(`INSERT INTO user_roles (user_id, role_id, created_at)
+			SELECT u.id, r.id, now() FROM users u JOIN roles r ON r.name = u.role
+			WHERE NOT EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id)`).Error
+	})
+}
+
+func (r *roleRepository) ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("name ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetRoleByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	return &role, err
+}
+
+func (r *roleRepository) CreateRole(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+func (r *roleRepository) UpdateRole(role *models.Role, permissions []models.Permission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		if permissions == nil {
+			return nil
+		}
+		return tx.Model(role).Association("Permissions").Replace(permissions)
+	})
+}
+
+func (r *roleRepository) DeleteRole(role *models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+}
+
+func (r *roleRepository) CountUsersWithPrimaryRole(name string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
+
+func (r *roleRepository) ListPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Order("name ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepository) GetPermissionsByName(names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepository) GetUserRoles(userID string) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetRoleNamesByUserIDs(userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	var rows []struct {
+		UserID uuid.UUID
+		Name   string
+	}
+	err := r.db.Model(&models.UserRole{}).
+		Select("user_roles.user_id, roles.name").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id IN ?", userIDs).
+		Order("roles.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID][]string, len(userIDs))
+	for _, row := range rows {
+		result[row.UserID] = append(result[row.UserID], row.Name)
+	}
+	return result, nil
+}
+
+func (r *roleRepository) AssignRole(userID, roleID uuid.UUID) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserRole{UserID: userID, RoleID: roleID}).Error
+}
+
+func (r *roleRepository) RevokeRole(userID, roleID uuid.UUID) error {
+	return r.db.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{}).Error
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestSeedDefaultsOnExistingDatabase(t *testing.T) {
+	db := testDB(t)
+	repo := NewRoleRepository(db)
+
+	if err := repo.SeedDefaults(); err != nil {
+		t.Fatalf("first SeedDefaults() error = %v", err)
+	}
+
+	customer, err := repo.GetRoleByName("customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes made between two starts of the service
+	custom := models.Permission{Name: "test:custom-" + uuid.NewString()}
+	if err := db.Create(&custom).Error; err != nil {
+		t.Fatal(err)
+	}
+	pharmacist, err := repo.GetRoleByName("pharmacist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited, err := repo.GetPermissionsByName([]string{"profile:read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateRole(pharmacist, edited); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Username: "seed-" + uuid.NewString()[:8], Email: uuid.NewString() + "@example.com", PasswordHash: "x", Role: "customer"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// The seed runs on every start, so it must succeed any number of times
+	for run := 2; run <= 3; run++ {
+		if err := repo.SeedDefaults(); err != nil {
+			t.Fatalf("SeedDefaults() run %d error = %v", run, err)
+		}
+	}
+
+	allPermissions, err := repo.ListPermissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role string
+		want []string
+	}{
+		{role: "admin", want: permissionNames(allPermissions)},
+		{role: "pharmacist", want: []string{"profile:read"}},
+		{role: "customer", want: permissionNames(customer.Permissions)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			role, err := repo.GetRoleByName(tt.role)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := permissionNames(role.Permissions); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("%s permissions = %v, want %v", tt.role, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("user without roles", func(t *testing.T) {
+		roles, err := repo.GetUserRoles(user.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(roles) != 1 || roles[0].Name != "customer" {
+			t.Fatalf("user roles = %v, want only customer", roles)
+		}
+	})
+}
+
+func permissionNames(permissions []models.Permission) []string {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+)
+
+type SigningKeyRepository interface {
+	GetActiveKey() (*models.SigningKey, error)
+	GetKeyByID(id string) (*models.SigningKey, error)
+	RotateKey(key *models.SigningKey) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) SigningKeyRepository
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{db}
+}
+
+func (r *signingKeyRepository) WithContext(ctx context.Context) SigningKeyRepository {
+	return &signingKeyRepository{r.db.WithContext(ctx)}
+}
+
+func (r *signingKeyRepository) GetActiveKey() (*models.SigningKey, error) {
+	var key models.SigningKey
+	err := r.db.Where("retired_at IS NULL").Order("created_at DESC").First(&key).Error
+	return &key, err
+}
+
+func (r *signingKeyRepository) GetKeyByID(id string) (*models.SigningKey, error) {
+	var key models.SigningKey
+	err := r.db.Where("id = ?", id).First(&key).Error
+	return &key, err
+}
+
+// RotateKey retires every active key and stores the given key as the new active one.
+// Retired keys are kept so that tokens signed with them can still be verified.
+func (r *signingKeyRepository) RotateKey(key *models.SigningKey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SigningKey{}).Where("retired_at IS NULL").Update("retired_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+}
@@ -1,16 +1,65 @@
 package repositories
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/PharmaKart/authentication-svc/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// UserFilter restricts a user listing to rows matching "Column Operator Value"
+type UserFilter struct {
+	Column   string
+	Operator string
+	Value    interface{}
+}
+
+// UserQuery describes one page of a user listing.
+// When AfterID is set the page starts after the row with that ID and sort value.
+type UserQuery struct {
+	Filters    []UserFilter
+	SortBy     string
+	Desc       bool
+	AfterValue interface{}
+	AfterID    string
+	Limit      int
+}
+
+var userQueryColumns = map[string]bool{
+	"username":    true,
+	"email":       true,
+	"role":        true,
+	"created_at":  true,
+	"disabled_at": true,
+}
+
+var userQueryOperators = map[string]bool{
+	"=":           true,
+	"!=":          true,
+	">":           true,
+	">=":          true,
+	"<":           true,
+	"<=":          true,
+	"ILIKE":       true,
+	"IS NULL":     true,
+	"IS NOT NULL": true,
+}
+
 type UserRepository interface {
-	CreateUser(user *models.User) (uuid.UUID, error)
+	CreateUser(user *models.User, roleID uuid.UUID, events ...*models.OutboxEvent) (uuid.UUID, error)
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
 	GetUserByUserName(username string) (*models.User, error)
+	ListUsers(query UserQuery) ([]models.User, error)
+	UpdateUser(user *models.User, events ...*models.OutboxEvent) error
+	UpdateUserRole(user *models.User, previousRoleID, roleID uuid.UUID, events ...*models.OutboxEvent) error
+	EraseUser(id string, deletionID uuid.UUID, erasedAt time.Time, events ...*models.OutboxEvent) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) UserRepository
 }
 
 type userRepository struct {
@@ -21,8 +70,26 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db}
 }
 
-func (r *userRepository) CreateUser(user *models.User) (uuid.UUID, error) {
-	if err := r.db.Create(user).Error; err != nil {
+func (r *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{r.db.WithContext(ctx)}
+}
+
+// CreateUser adds the user with the role and the events about it in one transaction.
+// The ID of the new user is set on the events.
+func (r *userRepository) CreateUser(user *models.User, roleID uuid.UUID, events ...*models.OutboxEvent) (uuid.UUID, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			event.UserID = user.ID
+		}
+		return createOutboxEvents(tx, events)
+	})
+	if err != nil {
 		return uuid.Nil, err
 	}
 	return user.ID, nil
@@ -45,3 +112,158 @@ func (r *userRepository) GetUserByUserName(username string) (*models.User, error
 	err := r.db.Where("username = ?", username).First(&user).Error
 	return &user, err
 }
+
+func (r *userRepository) ListUsers(query UserQuery) ([]models.User, error) {
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	if !userQueryColumns[sortBy] {
+		return nil, fmt.Errorf("unsupported sort column %q", sortBy)
+	}
+
+	// Erased accounts are tombstones and not listed
+	tx := r.db.Where("erased_at IS NULL")
+	for _, filter := range query.Filters {
+		if !userQueryColumns[filter.Column] || !userQueryOperators[filter.Operator] {
+			return nil, fmt.Errorf("unsupported filter %q %q", filter.Column, filter.Operator)
+		}
+		if filter.Operator == "IS NULL" || filter.Operator == "IS NOT NULL" {
+			tx = tx.Where(fmt.Sprintf("%s %s", filter.Column, filter.Operator))
+		} else {
+			tx = tx.Where(fmt.Sprintf("%s %s ?", filter.Column, filter.Operator), filter.Value)
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if query.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Keyset pagination on (sort column, id) keeps pages stable while rows are inserted
+	if query.AfterID != "" {
+		tx = tx.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortBy, comparison), query.AfterValue, query.AfterID)
+	}
+
+	var users []models.User
+	err := tx.Order(fmt.Sprintf("%s %s, id %s", sortBy, direction, direction)).Limit(query.Limit).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) UpdateUser(user *models.User, events ...*models.OutboxEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return createOutboxEvents(tx, events)
+	})
+}
+
+// UpdateUserRole saves the user after a change of primary role, revoking the previous role and granting the new one,
+// together with the events about it. previousRoleID is uuid.Nil when the previous role no longer exists.
+func (r *userRepository) UpdateUserRole(user *models.User, previousRoleID, roleID uuid.UUID, events ...*models.OutboxEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if previousRoleID != uuid.Nil {
+			if err := tx.Where("user_id = ? AND role_id = ?", user.ID, previousRoleID).Delete(&models.UserRole{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return createOutboxEvents(tx, events)
+	})
+}
+
+// EraseUser removes the personal information of a user while keeping the user row as a tombstone,
+// so that references from other tables stay valid. The user's dependents, addresses and data exports are deleted,
+// the customer record is anonymized and delegations to or from the user are revoked.
+// Every event and webhook delivery about the user is deleted, whether sent, pending or failed, since their payloads
+// hold personal information; the given events are added.
+// The deletion request is completed in the same transaction, so an erased account is never erased again.
+func (r *userRepository) EraseUser(id string, deletionID uuid.UUID, erasedAt time.Time, events ...*models.OutboxEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("id = ?", id).First(&user).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.DataExport{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.OutboxEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.WebhookDeadLetter{}).Error; err != nil {
+			return err
+		}
+		if err := createOutboxEvents(tx, events); err != nil {
+			return err
+		}
+
+		customerIDs := tx.Model(&models.Customer{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Where("customer_id IN (?)", customerIDs).Delete(&models.Address{}).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.Customer{}).Where("user_id = ?", id).Updates(map[string]interface{}{
+			"first_name":    "Deleted",
+			"last_name":     "Customer",
+			"phone":         nil,
+			"date_of_birth": nil,
+			"version":       gorm.Expr("version + 1"),
+			"updated_at":    erasedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		dependentIDs := tx.Model(&models.Dependent{}).Select("id").Where("owner_id = ?", id)
+		err = tx.Model(&models.DelegationGrant{}).
+			Where("revoked_at IS NULL").
+			Where("delegate_id = ? OR granted_by = ? OR subject_id = ? OR subject_id IN (?)", id, id, id, dependentIDs).
+			Update("revoked_at", erasedAt).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("owner_id = ?", id).Delete(&models.Dependent{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.AccountDeletion{}).Where("id = ?", deletionID).Update("completed_at", erasedAt).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Invitation{}).Where("email = ?", user.Email).
+			Update("email", "erased-"+id+"@erased.invalid").Error
+		if err != nil {
+			return err
+		}
+
+		// The password hash is cleared and the account disabled, so no token or login works any more
+		disabledAt := erasedAt
+		if user.DisabledAt != nil {
+			disabledAt = *user.DisabledAt
+		}
+		return tx.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"username":      "deleted-" + id,
+			"email":         "deleted-" + id + "@erased.invalid",
+			"password_hash": "",
+			"store_id":      nil,
+			"disabled_at":   disabledAt,
+			"erased_at":     erasedAt,
+		}).Error
+	})
+}
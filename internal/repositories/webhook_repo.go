@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	GetSubscription(id string) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	GetActiveSubscriptions() ([]models.WebhookSubscription, error)
+	UpdateSubscription(subscription *models.WebhookSubscription) error
+	DeleteSubscription(id string) error
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	DeleteDeliveredDeliveries(before time.Time) (int64, error)
+	DeadLetterDelivery(delivery *models.WebhookDelivery) (*models.WebhookDeadLetter, error)
+	ListDeadLetters(subscriptionID string, limit, offset int) ([]models.WebhookDeadLetter, error)
+	GetDeadLetter(id string) (*models.WebhookDeadLetter, error)
+	ReplayDeadLetter(deadLetter *models.WebhookDeadLetter, delivery *models.WebhookDelivery) error
+	// WithContext returns the repository running its queries with ctx, so they are traced as part of the call
+	WithContext(ctx context.Context) WebhookRepository
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db}
+}
+
+func (r *webhookRepository) WithContext(ctx context.Context) WebhookRepository {
+	return &webhookRepository{r.db.WithContext(ctx)}
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *webhookRepository) GetSubscription(id string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.db.Where("id = ?", id).First(&subscription).Error
+	return &subscription, err
+}
+
+func (r *webhookRepository) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Order("created_at ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookRepository) GetActiveSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Where("active").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	return r.db.Save(subscription).Error
+}
+
+// DeleteSubscription removes the subscription with its pending deliveries and dead letters
+func (r *webhookRepository) DeleteSubscription(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDeadLetter{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.WebhookSubscription{}).Error
+	})
+}
+
+// CreateDeliveries queues the deliveries, skipping events already queued for the same subscription
+func (r *webhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDueDeliveries returns up to limit deliveries due for sending and pushes their next attempt back by lease,
+// so that other workers skip them meanwhile
+func (r *webhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND next_attempt_at <= ?", now).
+			Order("created_at ASC").Limit(limit).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID.String())
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// DeleteDeliveredDeliveries removes the deliveries that succeeded before the given time
+func (r *webhookRepository) DeleteDeliveredDeliveries(before time.Time) (int64, error) {
+	result := r.db.Where("delivered_at < ?", before).Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+// DeadLetterDelivery moves a delivery that failed every attempt to the dead-letter table
+func (r *webhookRepository) DeadLetterDelivery(delivery *models.WebhookDelivery) (*models.WebhookDeadLetter, error) {
+	deadLetter := &models.WebhookDeadLetter{
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		UserID:         delivery.UserID,
+		Payload:        delivery.Payload,
+		Attempts:       delivery.Attempts,
+		LastStatus:     delivery.LastStatus,
+		LastError:      delivery.LastError,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(deadLetter).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", delivery.ID).Delete(&models.WebhookDelivery{}).Error
+	})
+	return deadLetter, err
+}
+
+// ListDeadLetters returns dead letters, newest first, optionally of one subscription
+func (r *webhookRepository) ListDeadLetters(subscriptionID string, limit, offset int) ([]models.WebhookDeadLetter, error) {
+	tx := r.db
+	if subscriptionID != "" {
+		tx = tx.Where("subscription_id = ?", subscriptionID)
+	}
+
+	var deadLetters []models.WebhookDeadLetter
+	err := tx.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&deadLetters).Error
+	return deadLetters, err
+}
+
+func (r *webhookRepository) GetDeadLetter(id string) (*models.WebhookDeadLetter, error) {
+	var deadLetter models.WebhookDeadLetter
+	err := r.db.Where("id = ?", id).First(&deadLetter).Error
+	return &deadLetter, err
+}
+
+// ReplayDeadLetter marks the dead letter as replayed and queues the delivery
+func (r *webhookRepository) ReplayDeadLetter(deadLetter *models.WebhookDeadLetter, delivery *models.WebhookDelivery) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(deadLetter).Error; err != nil {
+			return err
+		}
+		return tx.Create(delivery).Error
+	})
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AddressInput holds the fields of a new or updated address
+type AddressInput struct {
+	Label           string
+	StreetLine1     string
+	StreetLine2     string
+	City            string
+	Province        string
+	PostalCode      string
+	Country         string
+	DefaultShipping bool
+	DefaultBilling  bool
+}
+
+type AddressService interface {
+	ListAddresses(ctx context.Context, userID string) ([]models.Address, error)
+	CreateAddress(ctx context.Context, userID string, input AddressInput) (*models.Address, error)
+	UpdateAddress(ctx context.Context, userID, addressID string, input AddressInput) (*models.Address, error)
+	DeleteAddress(ctx context.Context, userID, addressID string) error
+}
+
+type addressService struct {
+	customerRepo repositories.CustomerRepository
+	addressRepo  repositories.AddressRepository
+}
+
+func NewAddressService(customerRepo repositories.CustomerRepository, addressRepo repositories.AddressRepository) AddressService {
+	return &addressService{
+		customerRepo: customerRepo,
+		addressRepo:  addressRepo,
+	}
+}
+
+func (s *addressService) ListAddresses(ctx context.Context, userID string) ([]models.Address, error) {
+	customer, err := s.customer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := s.addressRepo.WithContext(ctx).GetAddressesByCustomerID(customer.ID.String())
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return addresses, nil
+}
+
+// CreateAddress adds an address. The customer's first address becomes both default shipping and default billing.
+func (s *addressService) CreateAddress(ctx context.Context, userID string, input AddressInput) (*models.Address, error) {
+	input.Province, input.PostalCode, input.Country = utils.NormalizeAddress(input.Province, input.PostalCode, input.Country)
+	if err := utils.ValidateAddressInput(input.Label, input.StreetLine1, input.City, input.Province, input.PostalCode, input.Country); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.customer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.addressRepo.WithContext(ctx).GetAddressesByCustomerID(customer.ID.String())
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	address := &models.Address{CustomerID: customer.ID}
+	applyAddressInput(address, input)
+	if len(existing) == 0 {
+		address.IsDefaultShipping = true
+		address.IsDefaultBilling = true
+	}
+
+	if err := s.addressRepo.WithContext(ctx).CreateAddress(address); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Address created", map[string]interface{}{
+		"userID":    userID,
+		"addressID": address.ID.String(),
+	})
+
+	return address, nil
+}
+
+// UpdateAddress replaces the fields of one of the customer's addresses.
+// A default cannot be unset directly; another address has to be made the default instead.
+func (s *addressService) UpdateAddress(ctx context.Context, userID, addressID string, input AddressInput) (*models.Address, error) {
+	input.Province, input.PostalCode, input.Country = utils.NormalizeAddress(input.Province, input.PostalCode, input.Country)
+	if err := utils.ValidateAddressInput(input.Label, input.StreetLine1, input.City, input.Province, input.PostalCode, input.Country); err != nil {
+		return nil, err
+	}
+
+	address, err := s.address(ctx, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	if address.IsDefaultShipping && !input.DefaultShipping {
+		return nil, errors.NewValidationError("default_shipping", "address.default_shipping_required")
+	}
+	if address.IsDefaultBilling && !input.DefaultBilling {
+		return nil, errors.NewValidationError("default_billing", "address.default_billing_required")
+	}
+
+	applyAddressInput(address, input)
+	if err := s.addressRepo.WithContext(ctx).UpdateAddress(address); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Address updated", map[string]interface{}{
+		"userID":    userID,
+		"addressID": addressID,
+	})
+
+	return address, nil
+}
+
+// DeleteAddress removes one of the customer's addresses. The last address cannot be removed.
+func (s *addressService) DeleteAddress(ctx context.Context, userID, addressID string) error {
+	address, err := s.address(ctx, userID, addressID)
+	if err != nil {
+		return err
+	}
+
+	addresses, err := s.addressRepo.WithContext(ctx).GetAddressesByCustomerID(address.CustomerID.String())
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+	if len(addresses) <= 1 {
+		return errors.NewConflictError("address.last_address")
+	}
+
+	if err := s.addressRepo.WithContext(ctx).DeleteAddress(address); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Address deleted", map[string]interface{}{
+		"userID":    userID,
+		"addressID": addressID,
+	})
+
+	return nil
+}
+
+// customer returns the customer record of the user
+func (s *addressService) customer(ctx context.Context, userID string) (*models.Customer, error) {
+	customer, err := s.customerRepo.WithContext(ctx).GetCustomerByUserID(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("profile.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return customer, nil
+}
+
+// address returns one of the user's addresses. Addresses of other customers are reported as not found.
+func (s *addressService) address(ctx context.Context, userID, addressID string) (*models.Address, error) {
+	if _, err := uuid.Parse(addressID); err != nil {
+		return nil, errors.NewValidationError("address_id", "address.invalid_id")
+	}
+
+	customer, err := s.customer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.addressRepo.WithContext(ctx).GetAddressByID(addressID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("address.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	if address.CustomerID != customer.ID {
+		return nil, errors.NewNotFoundError("address.not_found")
+	}
+
+	return address, nil
+}
+
+func applyAddressInput(address *models.Address, input AddressInput) {
+	streetLine2 := strings.TrimSpace(input.StreetLine2)
+
+	address.Label = strings.TrimSpace(input.Label)
+	address.StreetLine1 = strings.TrimSpace(input.StreetLine1)
+	address.StreetLine2 = &streetLine2
+	address.City = strings.TrimSpace(input.City)
+	address.Province = strings.TrimSpace(input.Province)
+	address.PostalCode = strings.TrimSpace(input.PostalCode)
+	address.Country = strings.TrimSpace(input.Country)
+	address.IsDefaultShipping = input.DefaultShipping
+	address.IsDefaultBilling = input.DefaultBilling
+}
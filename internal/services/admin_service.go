@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Filter is a column/operator/value condition on a listing
+type Filter struct {
+	Column   string
+	Operator string
+	Value    string
+}
+
+// UserDetails is a user together with the names of all roles they hold
+type UserDetails struct {
+	models.User
+	Roles []string
+}
+
+type AdminService interface {
+	CreateUser(ctx context.Context, actorID, username, email, password, role string) (string, error)
+	GetUser(ctx context.Context, userID string) (*UserDetails, error)
+	ListUsers(ctx context.Context, filters []Filter, pageSize int, pageToken, sortBy, sortOrder string) ([]UserDetails, string, error)
+	UpdateUser(ctx context.Context, actorID, userID string, username, email, role *string) (*UserDetails, error)
+	DisableUser(ctx context.Context, actorID, userID string) error
+	EnableUser(ctx context.Context, actorID, userID string) error
+	DeleteUser(ctx context.Context, actorID, userID string) error
+}
+
+type adminService struct {
+	userRepo       repositories.UserRepository
+	roleRepo       repositories.RoleRepository
+	authService    AuthService
+	erasureService ErasureService
+	audit          AuditService
+}
+
+func NewAdminService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, authService AuthService, erasureService ErasureService, auditService AuditService) AdminService {
+	return &adminService{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		authService:    authService,
+		erasureService: erasureService,
+		audit:          auditService,
+	}
+}
+
+func (s *adminService) CreateUser(ctx context.Context, actorID, username, email, password, role string) (string, error) {
+	return s.authService.CreateUser(ctx, actorID, username, email, password, role)
+}
+
+func (s *adminService) GetUser(ctx context.Context, userID string) (*UserDetails, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := s.withRoles(ctx, []models.User{*user})
+	if err != nil {
+		return nil, err
+	}
+	return &details[0], nil
+}
+
+func (s *adminService) ListUsers(ctx context.Context, filters []Filter, pageSize int, pageToken, sortBy, sortOrder string) ([]UserDetails, string, error) {
+	query := repositories.UserQuery{}
+
+	// Validate sorting
+	switch sortBy {
+	case "":
+		query.SortBy = "created_at"
+	case "created_at", "username", "email":
+		query.SortBy = sortBy
+	default:
+		return nil, "", errors.NewValidationError("sort_by", "admin.sort_invalid")
+	}
+
+	switch strings.ToLower(sortOrder) {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return nil, "", errors.NewValidationError("sort_order", "admin.sort_order_invalid")
+	}
+
+	// Validate the page size
+	switch {
+	case pageSize < 0:
+		return nil, "", errors.NewValidationError("page_size", "page.size_negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	// Fetch one extra row to know whether another page follows
+	query.Limit = pageSize + 1
+
+	// Translate the filters
+	for i, filter := range filters {
+		userFilter, err := toUserFilter(fmt.Sprintf("filters[%d]", i), filter)
+		if err != nil {
+			return nil, "", err
+		}
+		query.Filters = append(query.Filters, userFilter)
+	}
+
+	// Resume after the last row of the previous page
+	if pageToken != "" {
+		cursor, err := decodeUserCursor(pageToken)
+		if err != nil || cursor.SortBy != query.SortBy || cursor.Desc != query.Desc {
+			return nil, "", errors.NewValidationError("page_token", "page.invalid_token")
+		}
+		query.AfterID = cursor.ID
+		query.AfterValue = cursor.Value
+		if query.SortBy == "created_at" {
+			createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return nil, "", errors.NewValidationError("page_token", "page.invalid_token")
+			}
+			query.AfterValue = createdAt
+		}
+	}
+
+	users, err := s.userRepo.WithContext(ctx).ListUsers(query)
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	nextPageToken := ""
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		nextPageToken = encodeUserCursor(query, users[len(users)-1])
+	}
+
+	details, err := s.withRoles(ctx, users)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return details, nextPageToken, nil
+}
+
+func (s *adminService) UpdateUser(ctx context.Context, actorID, userID string, username, email, role *string) (*UserDetails, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the changed fields
+	if err := utils.ValidateUserUpdate(username, email, role); err != nil {
+		return nil, err
+	}
+
+	// Check that the new username and email are not taken
+	changes := make(map[string]interface{})
+	if username != nil && *username != user.Username {
+		if _, err := s.userRepo.WithContext(ctx).GetUserByUserName(*username); err == nil {
+			return nil, errors.NewConflictError("user.username_taken").WithParams("username", *username)
+		}
+		user.Username = *username
+		changes["username"] = user.Username
+	}
+
+	if email != nil && *email != user.Email {
+		if _, err := s.userRepo.WithContext(ctx).GetUserByEmail(*email); err == nil {
+			return nil, errors.NewConflictError("user.email_taken").WithParams("email", *email)
+		}
+		user.Email = *email
+		changes["email"] = user.Email
+	}
+
+	// The new primary role replaces the previous one; other roles held by the user are kept
+	previousRole := user.Role
+	var previousRoleID, newRoleID uuid.UUID
+	if role != nil && *role != user.Role {
+		newRole, err := s.roleRepo.WithContext(ctx).GetRoleByName(*role)
+		if err != nil {
+			return nil, errors.NewFieldError("role", i18n.M("role.not_exist", "role", *role))
+		}
+		newRoleID = newRole.ID
+
+		oldRole, err := s.roleRepo.WithContext(ctx).GetRoleByName(previousRole)
+		if err == nil {
+			previousRoleID = oldRole.ID
+		} else if !goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewInternalError(err)
+		}
+
+		user.Role = *role
+		changes["role"] = user.Role
+	}
+
+	var events []*models.OutboxEvent
+	if len(changes) > 0 {
+		events = append(events, models.NewOutboxEvent(models.EventUserUpdated, user.ID, map[string]interface{}{
+			"changes": changes,
+		}))
+	}
+	if newRoleID != uuid.Nil {
+		err = s.userRepo.WithContext(ctx).UpdateUserRole(user, previousRoleID, newRoleID, events...)
+	} else {
+		err = s.userRepo.WithContext(ctx).UpdateUser(user, events...)
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("User updated", map[string]interface{}{
+		"userID":   user.ID.String(),
+		"username": user.Username,
+		"role":     user.Role,
+	})
+	if user.Role != previousRole {
+		s.audit.Record(ctx, "user.role_changed", actorID, userID, map[string]interface{}{
+			"from": previousRole,
+			"to":   user.Role,
+		})
+	}
+
+	return s.GetUser(ctx, userID)
+}
+
+func (s *adminService) DisableUser(ctx context.Context, actorID, userID string) error {
+	if actorID == userID {
+		return errors.NewBadRequestError("admin.disable_self")
+	}
+	return s.authService.DisableUser(ctx, actorID, userID)
+}
+
+func (s *adminService) EnableUser(ctx context.Context, actorID, userID string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.ErasedAt != nil {
+		return errors.NewConflictError("admin.enable_erased")
+	}
+	if user.DisabledAt == nil {
+		return errors.NewConflictError("admin.user_not_disabled")
+	}
+
+	user.DisabledAt = nil
+	updated := models.NewOutboxEvent(models.EventUserUpdated, user.ID, map[string]interface{}{
+		"changes": map[string]interface{}{"disabled": false},
+	})
+	if err := s.userRepo.WithContext(ctx).UpdateUser(user, updated); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("User enabled", map[string]interface{}{
+		"userID": userID,
+	})
+	s.audit.Record(ctx, "user.enabled", actorID, userID, nil)
+
+	return nil
+}
+
+// DeleteUser erases the user's personal information right away, through the same pipeline as self-service deletion
+func (s *adminService) DeleteUser(ctx context.Context, actorID, userID string) error {
+	if actorID == userID {
+		return errors.NewBadRequestError("admin.delete_self")
+	}
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.erasureService.EraseNow(ctx, actorID, userID)
+}
+
+func (s *adminService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, errors.NewValidationError("user_id", "user.invalid_id")
+	}
+
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("user.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return user, nil
+}
+
+// withRoles loads the role names of the given users
+func (s *adminService) withRoles(ctx context.Context, users []models.User) ([]UserDetails, error) {
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	roles := map[uuid.UUID][]string{}
+	if len(userIDs) > 0 {
+		var err error
+		roles, err = s.roleRepo.WithContext(ctx).GetRoleNamesByUserIDs(userIDs)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+	}
+
+	details := make([]UserDetails, 0, len(users))
+	for _, user := range users {
+		details = append(details, UserDetails{User: user, Roles: roles[user.ID]})
+	}
+	return details, nil
+}
+
+var filterOperators = map[string]string{
+	"=":        "=",
+	"eq":       "=",
+	"!=":       "!=",
+	"ne":       "!=",
+	"neq":      "!=",
+	">":        ">",
+	"gt":       ">",
+	">=":       ">=",
+	"gte":      ">=",
+	"<":        "<",
+	"lt":       "<",
+	"<=":       "<=",
+	"lte":      "<=",
+	"contains": "ILIKE",
+	"like":     "ILIKE",
+}
+
+// toUserFilter validates a filter and converts it to a repository filter. Errors name the filter as field.
+func toUserFilter(field string, filter Filter) (repositories.UserFilter, error) {
+	operator, ok := filterOperators[strings.ToLower(filter.Operator)]
+	if !ok {
+		return repositories.UserFilter{}, errors.NewFieldError(field, i18n.M("admin.filter_operator_unsupported", "operator", filter.Operator))
+	}
+
+	switch filter.Column {
+	case "username", "email", "role":
+		value := filter.Value
+		if operator == "ILIKE" {
+			value = "%" + escapeLike(value) + "%"
+		}
+		return repositories.UserFilter{Column: filter.Column, Operator: operator, Value: value}, nil
+
+	case "created_at":
+		if operator == "ILIKE" {
+			return repositories.UserFilter{}, errors.NewFieldError(field, i18n.M("admin.filter_created_at_operator", "operator", filter.Operator))
+		}
+		createdAt, err := time.Parse(time.RFC3339, filter.Value)
+		if err != nil {
+			return repositories.UserFilter{}, errors.NewValidationError(field, "admin.filter_created_at_invalid")
+		}
+		return repositories.UserFilter{Column: "created_at", Operator: operator, Value: createdAt}, nil
+
+	case "disabled":
+		disabled, err := strconv.ParseBool(filter.Value)
+		if err != nil || (operator != "=" && operator != "!=") {
+			return repositories.UserFilter{}, errors.NewValidationError(field, "admin.filter_disabled_invalid")
+		}
+		if disabled == (operator == "=") {
+			return repositories.UserFilter{Column: "disabled_at", Operator: "IS NOT NULL"}, nil
+		}
+		return repositories.UserFilter{Column: "disabled_at", Operator: "IS NULL"}, nil
+	}
+
+	return repositories.UserFilter{}, errors.NewFieldError(field, i18n.M("admin.filter_column_unsupported", "column", filter.Column))
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// userCursor is the decoded form of a ListUsers page token
+type userCursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+func encodeUserCursor(query repositories.UserQuery, last models.User) string {
+	cursor := userCursor{SortBy: query.SortBy, Desc: query.Desc, ID: last.ID.String()}
+	switch query.SortBy {
+	case "username":
+		cursor.Value = last.Username
+	case "email":
+		cursor.Value = last.Email
+	default:
+		cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(token string) (*userCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor userCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
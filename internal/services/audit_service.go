@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// auditVerifyBatchSize is the number of events read at a time when verifying the chain
+const auditVerifyBatchSize = 1000
+
+// AuditFilter restricts a listing of audit events
+type AuditFilter struct {
+	Action    string
+	ActorID   string
+	SubjectID string
+	From      string // RFC 3339
+	To        string // RFC 3339
+}
+
+// ChainReport is the result of verifying the audit chain
+type ChainReport struct {
+	Checked  int64  // number of events verified
+	Valid    bool   // false when the chain is broken
+	BrokenAt int64  // sequence of the first event that does not verify
+	Reason   string // why that event does not verify
+}
+
+type AuditService interface {
+	Record(ctx context.Context, action, actorID, subjectID string, details map[string]interface{})
+	QueryEvents(ctx context.Context, filter AuditFilter, pageSize int, pageToken string) ([]models.AuditEvent, string, error)
+	VerifyChain(ctx context.Context) (*ChainReport, error)
+}
+
+type auditService struct {
+	auditRepo repositories.AuditRepository
+}
+
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record appends an event to the audit chain. Actor and subject are user IDs and may be empty.
+// Details must not contain personal information. Failures are logged rather than returned
+// so that the audit store being unavailable does not block the action itself.
+func (s *auditService) Record(ctx context.Context, action, actorID, subjectID string, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		detailsJSON = []byte("{}")
+	}
+
+	event := &models.AuditEvent{
+		Action:    action,
+		ActorID:   parseOptionalUUID(actorID),
+		SubjectID: parseOptionalUUID(subjectID),
+		Details:   string(detailsJSON),
+	}
+	if err := s.auditRepo.WithContext(ctx).AppendEvent(event); err != nil {
+		utils.Error("Failed to record audit event", map[string]interface{}{
+			"action": action,
+			"error":  err.Error(),
+		})
+	}
+}
+
+// QueryEvents returns a page of audit events matching the filter, newest first
+func (s *auditService) QueryEvents(ctx context.Context, filter AuditFilter, pageSize int, pageToken string) ([]models.AuditEvent, string, error) {
+	validationErrors := make(map[string]string)
+	query := repositories.AuditQuery{Action: filter.Action}
+
+	if filter.ActorID != "" {
+		if _, err := uuid.Parse(filter.ActorID); err != nil {
+			validationErrors["actor_id"] = "audit.invalid_actor_id"
+		}
+		query.ActorID = filter.ActorID
+	}
+	if filter.SubjectID != "" {
+		if _, err := uuid.Parse(filter.SubjectID); err != nil {
+			validationErrors["subject_id"] = "auth.invalid_subject_id"
+		}
+		query.SubjectID = filter.SubjectID
+	}
+	if filter.From != "" {
+		from, err := time.Parse(time.RFC3339, filter.From)
+		if err != nil {
+			validationErrors["from"] = "audit.from_invalid"
+		}
+		query.From = &from
+	}
+	if filter.To != "" {
+		to, err := time.Parse(time.RFC3339, filter.To)
+		if err != nil {
+			validationErrors["to"] = "audit.to_invalid"
+		}
+		query.To = &to
+	}
+
+	switch {
+	case pageSize < 0:
+		validationErrors["page_size"] = "page.size_negative"
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	// The page token is the sequence of the last event of the previous page
+	if pageToken != "" {
+		sequence, err := strconv.ParseInt(pageToken, 10, 64)
+		if err != nil || sequence <= 0 {
+			validationErrors["page_token"] = "page.invalid_token"
+		}
+		query.BeforeSequence = sequence
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, "", errors.NewValidationErrors(validationErrors)
+	}
+
+	// Fetch one extra row to know whether another page follows
+	query.Limit = pageSize + 1
+	events, err := s.auditRepo.WithContext(ctx).QueryEvents(query)
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	nextPageToken := ""
+	if len(events) > pageSize {
+		events = events[:pageSize]
+		nextPageToken = strconv.FormatInt(events[len(events)-1].Sequence, 10)
+	}
+	return events, nextPageToken, nil
+}
+
+// VerifyChain checks every event in order: sequences must have no gaps, each event must link to
+// the hash of the one before it and its own hash must match its content
+func (s *auditService) VerifyChain(ctx context.Context) (*ChainReport, error) {
+	report := &ChainReport{Valid: true}
+	prevHash := models.AuditGenesisHash
+	var sequence int64
+
+	for {
+		events, err := s.auditRepo.WithContext(ctx).GetEventsAfter(sequence, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range events {
+			event := &events[i]
+			reason := ""
+			hash, err := event.ComputeHash()
+			switch {
+			case event.Sequence != sequence+1:
+				reason = fmt.Sprintf("expected sequence %d, events are missing", sequence+1)
+			case event.PrevHash != prevHash:
+				reason = "previous hash does not match the preceding event"
+			case err != nil:
+				reason = "details are not valid JSON"
+			case hash != event.Hash:
+				reason = "hash does not match the event's content"
+			}
+			if reason != "" {
+				report.Valid = false
+				report.BrokenAt = event.Sequence
+				report.Reason = reason
+				return report, nil
+			}
+
+			report.Checked++
+			sequence = event.Sequence
+			prevHash = event.Hash
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			return report, nil
+		}
+	}
+}
+
+func parseOptionalUUID(id string) *uuid.UUID {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -1,59 +1,121 @@
 package services
 
 import (
-	"fmt"
+	"context"
+	goerrors "errors"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/PharmaKart/authentication-svc/internal/metrics"
 	"github.com/PharmaKart/authentication-svc/internal/models"
 	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/internal/tracing"
 	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
 	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AuthService interface {
-	Register(username, email, password, firstName, lastName, phone, dob, streetLine1, streetLine2, city, province, postalCode, country string) error
-	Login(email, username, password string) (string, string, string, string, error)
-	VerifyToken(token string) (string, string, error)
+	Register(ctx context.Context, username, email, password, firstName, lastName, phone, dob, streetLine1, streetLine2, city, province, postalCode, country string, consents []ConsentInput) error
+	Login(ctx context.Context, email, username, password string) (*LoginResult, error)
+	VerifyToken(ctx context.Context, token string) (*utils.TokenClaims, error)
+	VerifyConsentToken(ctx context.Context, token string) (*utils.TokenClaims, error)
+	CheckPermission(ctx context.Context, token, permission string) (bool, error)
+	CreateUser(ctx context.Context, actorID, username, email, password, role string) (string, error)
+	FindUser(ctx context.Context, identifier string) (*models.User, error)
+	ResetPassword(ctx context.Context, actorID, userID, newPassword string) error
+	DisableUser(ctx context.Context, actorID, userID string) error
+	RotateSigningKey(ctx context.Context, actorID string) (string, error)
+	GetActingContext(ctx context.Context, token, subjectID string) (string, *utils.TokenClaims, error)
+}
+
+// actingTokenTTL is the longest lifetime of a token issued to act on behalf of someone else
+const actingTokenTTL = 15 * time.Minute
+
+// consentTokenTTL is the lifetime of a token only good for accepting the required policies
+const consentTokenTTL = 15 * time.Minute
+
+// LoginResult is a successful login. While RequiredPolicies is not empty, Token is only good for the consent RPCs,
+// and the user logs in again once they have accepted the policies.
+type LoginResult struct {
+	Token            string
+	UserID           string
+	Username         string
+	Role             string
+	RequiredPolicies []models.PolicyDocument
 }
 
 type authService struct {
-	userRepo     repositories.UserRepository
-	customerRepo repositories.CustomerRepository
-	jwtSecret    string
+	userRepo       repositories.UserRepository
+	customerRepo   repositories.CustomerRepository
+	signingKeyRepo repositories.SigningKeyRepository
+	roleRepo       repositories.RoleRepository
+	credentialRepo repositories.CredentialRepository
+	delegationRepo repositories.DelegationRepository
+	eligibility    EligibilityService
+	consents       ConsentService
+	audit          AuditService
+	minAge         int // minimum age to register as a customer
+	jwtSecret      string
 }
 
-func NewAuthService(userRepo repositories.UserRepository, customerRepo repositories.CustomerRepository, jwtSecret string) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, customerRepo repositories.CustomerRepository, signingKeyRepo repositories.SigningKeyRepository, roleRepo repositories.RoleRepository, credentialRepo repositories.CredentialRepository, delegationRepo repositories.DelegationRepository, eligibilityService EligibilityService, consentService ConsentService, auditService AuditService, minRegistrationAge int, jwtSecret string) AuthService {
 	return &authService{
-		userRepo:     userRepo,
-		customerRepo: customerRepo,
-		jwtSecret:    jwtSecret,
+		userRepo:       userRepo,
+		customerRepo:   customerRepo,
+		signingKeyRepo: signingKeyRepo,
+		roleRepo:       roleRepo,
+		credentialRepo: credentialRepo,
+		delegationRepo: delegationRepo,
+		eligibility:    eligibilityService,
+		consents:       consentService,
+		audit:          auditService,
+		minAge:         minRegistrationAge,
+		jwtSecret:      jwtSecret,
 	}
 }
 
-func (s *authService) Register(username, email, password, firstName, lastName, phone, dob, streetLine1, streetLine2, city, province, postalCode, country string) error {
+// Register creates a customer account. The consents must accept the current version of every mandatory policy
+// and may opt in to optional ones.
+func (s *authService) Register(ctx context.Context, username, email, password, firstName, lastName, phone, dob, streetLine1, streetLine2, city, province, postalCode, country string, consents []ConsentInput) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer func() { tracing.End(span, err) }()
+	defer func() { metrics.ObserveRegistration(err) }()
+
 	// Check if the user already exists by email
-	_, err := s.userRepo.GetUserByEmail(email)
+	_, err = s.userRepo.WithContext(ctx).GetUserByEmail(email)
 	if err == nil {
-		return errors.NewConflictError(fmt.Sprintf("User with email \"%s\" already exists", email))
+		return errors.NewConflictError("user.email_taken").WithParams("email", email)
 	}
 
 	// Check if the user already exists by username
-	_, err = s.userRepo.GetUserByUserName(username)
+	_, err = s.userRepo.WithContext(ctx).GetUserByUserName(username)
 	if err == nil {
-		return errors.NewConflictError(fmt.Sprintf("User with username \"%s\" already exists", username))
+		return errors.NewConflictError("user.username_taken").WithParams("username", username)
 	}
 
+	// Store regions as codes and postal codes in the country's format
+	province, postalCode, country = utils.NormalizeAddress(province, postalCode, country)
+
 	// Validate the user input
-	if err := utils.ValidateUserInput(username, email, password, firstName, lastName, phone, dob, streetLine1, city, province, postalCode, country); err != nil {
+	if err := utils.ValidateUserInput(username, email, password, firstName, lastName, phone, dob, streetLine1, city, province, postalCode, country, s.minAge); err != nil {
+		return err
+	}
+
+	// Check the accepted policies
+	if err := s.consents.CheckRegistrationConsents(ctx, consents); err != nil {
 		return err
 	}
 
 	// Hash the password
-	passwordHash, err := utils.HashPassword(password)
+	passwordHash, err := utils.HashPassword(ctx, password)
 	if err != nil {
 		return errors.NewInternalError(err)
 	}
 
-	// Add the user to the database
 	user := &models.User{
 		Username:     username,
 		Email:        email,
@@ -61,84 +123,630 @@ func (s *authService) Register(username, email, password, firstName, lastName, p
 		Role:         "customer", // Only customers can register
 	}
 
-	userID, err := s.userRepo.CreateUser(user)
+	role, err := s.roleRepo.WithContext(ctx).GetRoleByName(user.Role)
 	if err != nil {
 		return errors.NewInternalError(err)
 	}
 
+	// Store the phone number in E.164
+	phone, _ = utils.NormalizePhone(phone, country)
+
 	// Parse the date of birth
 	dobTime, err := utils.ParseDOB(dob)
 	if err != nil {
-		return errors.NewValidationError("dateOfBirth", "Invalid date format")
+		return errors.NewValidationError("dateOfBirth", "validation.date_of_birth_format")
 	}
 
-	// Add the customer to the database
+	// The registration address becomes the default address
 	customer := &models.Customer{
-		UserID:      userID,
 		FirstName:   firstName,
 		LastName:    lastName,
 		Phone:       &phone,
 		DateOfBirth: &dobTime,
-		StreetLine1: streetLine1,
-		StreetLine2: &streetLine2,
-		City:        city,
-		Province:    province,
-		PostalCode:  postalCode,
-		Country:     country,
+		Addresses: []models.Address{{
+			Label:             "Home",
+			StreetLine1:       streetLine1,
+			StreetLine2:       &streetLine2,
+			City:              city,
+			Province:          province,
+			PostalCode:        postalCode,
+			Country:           country,
+			IsDefaultShipping: true,
+			IsDefaultBilling:  true,
+		}},
+	}
+
+	// The accepted policies go to the consent ledger
+	consentEntries, err := s.consents.RegistrationConsents(ctx, consents)
+	if err != nil {
+		return err
 	}
 
-	_, err = s.customerRepo.CreateCustomer(customer)
+	// The user ID is set on the event when the user is created
+	registered := models.NewOutboxEvent(models.EventUserRegistered, uuid.Nil, map[string]interface{}{
+		"username":   user.Username,
+		"email":      user.Email,
+		"role":       user.Role,
+		"first_name": firstName,
+		"last_name":  lastName,
+		"source":     "registration",
+	})
+
+	// Add the user, role, customer and consents at once, so that a failure leaves the email and username free
+	userID, err := s.customerRepo.WithContext(ctx).RegisterCustomer(user, role.ID, customer, consentEntries, registered)
 	if err != nil {
 		return errors.NewInternalError(err)
 	}
 
+	s.audit.Record(ctx, "user.registered", userID.String(), userID.String(), map[string]interface{}{
+		"role": user.Role,
+	})
+
 	return nil
 }
 
-func (s *authService) Login(email, username, password string) (string, string, string, string, error) {
+func (s *authService) Login(ctx context.Context, email, username, password string) (_ *LoginResult, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer func() { tracing.End(span, err) }()
+
 	// Get the user from the database
 	var user *models.User
-	var err error
 
 	if username != "" {
-		user, err = s.userRepo.GetUserByUserName(username)
-		if err != nil {
-			return "", "", "", "", errors.NewNotFoundError("User not found")
-		}
+		user, err = s.userRepo.WithContext(ctx).GetUserByUserName(username)
 	} else {
-		user, err = s.userRepo.GetUserByEmail(email)
-		if err != nil {
-			return "", "", "", "", errors.NewNotFoundError("User not found")
-		}
+		user, err = s.userRepo.WithContext(ctx).GetUserByEmail(email)
+	}
+	if err != nil {
+		// The identifier is personal information and is not recorded
+		s.audit.Record(ctx, "auth.login_failed", "", "", map[string]interface{}{"reason": "unknown_user"})
+		metrics.ObserveLogin(metrics.LoginUnknownUser, "")
+		return nil, errors.NewNotFoundError("user.not_found")
 	}
 
 	// Check if the password is correct
-	err = utils.CheckPasswordHash(password, user.PasswordHash)
+	err = utils.CheckPasswordHash(ctx, password, user.PasswordHash)
 	if err != nil {
-		return "", "", "", "", errors.NewAuthError("Incorrect password")
+		s.audit.Record(ctx, "auth.login_failed", "", user.ID.String(), map[string]interface{}{"reason": "incorrect_password"})
+		metrics.ObserveLogin(metrics.LoginIncorrectPassword, user.Role)
+		return nil, errors.NewAuthError("auth.incorrect_password")
+	}
+
+	// Disabled accounts cannot log in
+	if user.DisabledAt != nil {
+		s.audit.Record(ctx, "auth.login_failed", "", user.ID.String(), map[string]interface{}{"reason": "account_disabled"})
+		metrics.ObserveLogin(metrics.LoginAccountDisabled, user.Role)
+		return nil, errors.NewAuthError("auth.account_disabled")
+	}
+
+	// Users who have not accepted the current mandatory policies only get a token to accept them with
+	required := []models.PolicyDocument{}
+	if !user.ConsentExempt {
+		required, err = s.consents.RequiredConsents(ctx, user.ID.String())
+		if err != nil {
+			metrics.ObserveLogin(metrics.LoginError, user.Role)
+			return nil, err
+		}
 	}
 
 	// Generate a JWT
-	token, err := utils.GenerateJWT(user.ID.String(), user.Role, s.jwtSecret)
+	var token string
+	if len(required) > 0 {
+		token, err = s.sign(ctx, utils.TokenClaims{
+			UserID:      user.ID.String(),
+			Roles:       []string{},
+			Permissions: []string{},
+			ConsentOnly: true,
+			ExpiresAt:   time.Now().Add(consentTokenTTL),
+		})
+	} else {
+		token, err = s.signToken(ctx, user)
+	}
 	if err != nil {
-		return "", "", "", "", errors.NewInternalError(err)
+		metrics.ObserveLogin(metrics.LoginError, user.Role)
+		return nil, errors.NewInternalError(err)
 	}
 
 	// Log
+	metrics.ObserveLogin(metrics.LoginSucceeded, user.Role)
 	utils.Info("User logged in", map[string]interface{}{
 		"userID":   user.ID.String(),
 		"username": user.Username,
 		"role":     user.Role,
 	})
+	s.audit.Record(ctx, "auth.login_succeeded", user.ID.String(), user.ID.String(), map[string]interface{}{
+		"role": user.Role,
+	})
+
+	return &LoginResult{
+		Token:            token,
+		UserID:           user.ID.String(),
+		Username:         user.Username,
+		Role:             user.Role,
+		RequiredPolicies: required,
+	}, nil
+}
+
+func (s authService) VerifyToken(ctx context.Context, token string) (_ *utils.TokenClaims, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyToken")
+	defer func() { tracing.End(span, err) }()
+
+	claims, err := utils.ValidateJWT(token, func(kid string) (string, error) {
+		return s.secretForKey(ctx, kid)
+	})
+	if err != nil {
+		metrics.ObserveTokenVerification(metrics.TokenInvalid)
+		return nil, errors.NewAuthError("auth.invalid_token")
+	}
+
+	// Tokens issued until the required policies are accepted only work for the consent RPCs
+	if claims.ConsentOnly {
+		metrics.ObserveTokenVerification(metrics.TokenInvalid)
+		return nil, errors.NewAuthError("auth.consent_required")
+	}
+
+	// Acting tokens are only valid while the actor is enabled and the delegation still holds
+	if claims.IsActing() {
+		actor, err := s.userRepo.WithContext(ctx).GetUserByID(claims.Actor)
+		if err != nil || actor.DisabledAt != nil {
+			metrics.ObserveTokenVerification(metrics.TokenDisabled)
+			return nil, errors.NewAuthError("auth.invalid_token")
+		}
+
+		scopes, _, err := s.actingScopes(ctx, claims.Actor, claims.Subject)
+		if err != nil {
+			metrics.ObserveTokenVerification(metrics.TokenRevoked)
+			return nil, errors.NewAuthError("auth.invalid_token")
+		}
+		claims.Permissions = intersect(claims.Permissions, scopes)
+
+		metrics.ObserveTokenVerification(metrics.TokenValid)
+		return claims, nil
+	}
+
+	// Tokens of disabled users are no longer accepted
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(claims.UserID)
+	if err != nil || user.DisabledAt != nil {
+		metrics.ObserveTokenVerification(metrics.TokenDisabled)
+		return nil, errors.NewAuthError("auth.invalid_token")
+	}
+
+	// Licenses expired, rejected or downgraded since the token was issued are no longer vouched for
+	claims.LicensedIn, _, err = s.credentialRepo.WithContext(ctx).GetLicensedProvinces(claims.UserID, time.Now())
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	metrics.ObserveTokenVerification(metrics.TokenValid)
+	return claims, nil
+}
+
+// VerifyConsentToken verifies a token for the consent RPCs, which also accept the tokens Login issues
+// to users who have policies to accept
+func (s authService) VerifyConsentToken(ctx context.Context, token string) (_ *utils.TokenClaims, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyConsentToken")
+	defer func() { tracing.End(span, err) }()
+
+	claims, err := utils.ValidateJWT(token, func(kid string) (string, error) {
+		return s.secretForKey(ctx, kid)
+	})
+	if err != nil || !claims.ConsentOnly {
+		return s.VerifyToken(ctx, token)
+	}
+
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(claims.UserID)
+	if err != nil || user.DisabledAt != nil {
+		metrics.ObserveTokenVerification(metrics.TokenDisabled)
+		return nil, errors.NewAuthError("auth.invalid_token")
+	}
+
+	metrics.ObserveTokenVerification(metrics.TokenValid)
+	return claims, nil
+}
+
+// CheckPermission reports whether the token's user currently holds the permission.
+// Permissions are resolved from the user's roles rather than the token so that revoked roles take effect immediately.
+func (s *authService) CheckPermission(ctx context.Context, token, permission string) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CheckPermission")
+	defer func() { tracing.End(span, err) }()
+
+	if strings.TrimSpace(permission) == "" {
+		return false, errors.NewValidationError("permission", "auth.permission_required")
+	}
+
+	claims, err := s.VerifyToken(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	// Acting tokens are limited to the delegated scopes, which VerifyToken has already re-checked
+	permissions := claims.Permissions
+	if !claims.IsActing() {
+		_, permissions, err = s.resolveRoles(ctx, claims.UserID)
+		if err != nil {
+			return false, errors.NewInternalError(err)
+		}
+	}
+
+	for _, p := range permissions {
+		if p == permission {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CreateUser creates a user with the role. actorID is the admin creating it, or empty when it is created from the CLI.
+func (s *authService) CreateUser(ctx context.Context, actorID, username, email, password, role string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateUser")
+	defer func() { tracing.End(span, err) }()
+
+	// Check if the user already exists by email
+	_, err = s.userRepo.WithContext(ctx).GetUserByEmail(email)
+	if err == nil {
+		return "", errors.NewConflictError("user.email_taken").WithParams("email", email)
+	}
+
+	// Check if the user already exists by username
+	_, err = s.userRepo.WithContext(ctx).GetUserByUserName(username)
+	if err == nil {
+		return "", errors.NewConflictError("user.username_taken").WithParams("username", username)
+	}
+
+	// Validate the user input
+	if err := utils.ValidateAccountInput(username, email, password, role); err != nil {
+		return "", err
+	}
+
+	// Check that the role exists
+	roleRecord, err := s.roleRepo.WithContext(ctx).GetRoleByName(role)
+	if err != nil {
+		return "", errors.NewFieldError("role", i18n.M("role.not_exist", "role", role))
+	}
+
+	// Hash the password
+	passwordHash, err := utils.HashPassword(ctx, password)
+	if err != nil {
+		return "", errors.NewInternalError(err)
+	}
+
+	// Accounts created from the CLI, such as the first admin, must be usable right away
+	user := &models.User{
+		Username:      username,
+		Email:         email,
+		PasswordHash:  passwordHash,
+		Role:          role,
+		ConsentExempt: actorID == "",
+	}
+
+	// The user ID is set on the event when the user is created
+	created := models.NewOutboxEvent(models.EventUserRegistered, uuid.Nil, map[string]interface{}{
+		"username": username,
+		"email":    email,
+		"role":     role,
+		"source":   "admin",
+	})
+	userID, err := s.userRepo.WithContext(ctx).CreateUser(user, roleRecord.ID, created)
+	if err != nil {
+		return "", errors.NewInternalError(err)
+	}
+
+	utils.Info("User created", map[string]interface{}{
+		"userID":   userID.String(),
+		"username": username,
+		"role":     role,
+	})
+	s.audit.Record(ctx, "user.created", actorID, userID.String(), map[string]interface{}{
+		"role": role,
+	})
+
+	return userID.String(), nil
+}
+
+// FindUser looks a user up by ID, email or username
+func (s *authService) FindUser(ctx context.Context, identifier string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.FindUser")
+	defer func() { tracing.End(span, err) }()
+
+	var user *models.User
+
+	if _, parseErr := uuid.Parse(identifier); parseErr == nil {
+		user, err = s.userRepo.WithContext(ctx).GetUserByID(identifier)
+	} else if strings.Contains(identifier, "@") {
+		user, err = s.userRepo.WithContext(ctx).GetUserByEmail(identifier)
+	} else {
+		user, err = s.userRepo.WithContext(ctx).GetUserByUserName(identifier)
+	}
+
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("user.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
 
-	return token, user.ID.String(), user.Username, user.Role, nil
+	return user, nil
 }
 
-func (s authService) VerifyToken(token string) (string, string, error) {
-	userID, role, err := utils.ValidateJWT(token, s.jwtSecret)
+func (s *authService) ResetPassword(ctx context.Context, actorID, userID, newPassword string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		return errors.NewNotFoundError("user.not_found")
+	}
+
+	if err := utils.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := utils.HashPassword(ctx, newPassword)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	user.PasswordHash = passwordHash
+	if err := s.userRepo.WithContext(ctx).UpdateUser(user, models.NewOutboxEvent(models.EventPasswordChanged, user.ID, nil)); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Password reset", map[string]interface{}{
+		"userID": userID,
+	})
+	s.audit.Record(ctx, "user.password_changed", actorID, userID, nil)
+
+	return nil
+}
+
+func (s *authService) DisableUser(ctx context.Context, actorID, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.DisableUser")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		return errors.NewNotFoundError("user.not_found")
+	}
+
+	if user.DisabledAt != nil {
+		return errors.NewConflictError("admin.user_already_disabled")
+	}
+
+	now := time.Now()
+	user.DisabledAt = &now
+	if err := s.userRepo.WithContext(ctx).UpdateUser(user, models.NewOutboxEvent(models.EventUserDisabled, user.ID, nil)); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("User disabled", map[string]interface{}{
+		"userID": userID,
+	})
+	// Tokens of disabled users are rejected, so disabling revokes them
+	s.audit.Record(ctx, "user.disabled", actorID, userID, map[string]interface{}{
+		"tokens_revoked": true,
+	})
+
+	return nil
+}
+
+// RotateSigningKey generates a new token signing key and retires the current one.
+// Tokens signed with retired keys stay valid until they expire.
+func (s *authService) RotateSigningKey(ctx context.Context, actorID string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RotateSigningKey")
+	defer func() { tracing.End(span, err) }()
+
+	kid, err := utils.GenerateRandomHex(8)
+	if err != nil {
+		return "", errors.NewInternalError(err)
+	}
+
+	secret, err := utils.GenerateRandomHex(32)
+	if err != nil {
+		return "", errors.NewInternalError(err)
+	}
+
+	if err := s.signingKeyRepo.WithContext(ctx).RotateKey(&models.SigningKey{ID: kid, Secret: secret}); err != nil {
+		return "", errors.NewInternalError(err)
+	}
+
+	utils.Info("Signing key rotated", map[string]interface{}{
+		"kid": kid,
+	})
+	s.audit.Record(ctx, "auth.signing_key_rotated", actorID, "", map[string]interface{}{
+		"kid": kid,
+	})
+
+	return kid, nil
+}
+
+// GetActingContext exchanges the caller's token for a short-lived token acting on behalf of the subject.
+// The new token carries the subject in "sub" and the caller in "act" so downstream services can tell who really acted.
+func (s *authService) GetActingContext(ctx context.Context, token, subjectID string) (_ string, _ *utils.TokenClaims, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetActingContext")
+	defer func() { tracing.End(span, err) }()
+
+	claims, err := s.VerifyToken(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Delegations do not chain
+	if claims.IsActing() {
+		return "", nil, errors.NewAuthError("auth.acting_token_exchange")
+	}
+
+	if _, err := uuid.Parse(subjectID); err != nil {
+		return "", nil, errors.NewValidationError("subject_id", "auth.invalid_subject_id")
+	}
+
+	scopes, grantExpiry, err := s.actingScopes(ctx, claims.UserID, subjectID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Age thresholds are those of the subject, so a caregiver cannot buy restricted products for a child
+	ageOver, err := s.eligibility.AgeOver(ctx, subjectID)
+	if err != nil {
+		return "", nil, errors.NewInternalError(err)
+	}
+
+	expiresAt := time.Now().Add(actingTokenTTL)
+	if grantExpiry != nil && grantExpiry.Before(expiresAt) {
+		expiresAt = *grantExpiry
+	}
+
+	actingClaims := &utils.TokenClaims{
+		UserID:      subjectID,
+		Role:        "customer",
+		Roles:       []string{"customer"},
+		Permissions: scopes,
+		AgeOver:     ageOver,
+		Subject:     subjectID,
+		Actor:       claims.UserID,
+		ExpiresAt:   expiresAt,
+	}
+
+	actingToken, err := s.sign(ctx, *actingClaims)
+	if err != nil {
+		return "", nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Acting token issued", map[string]interface{}{
+		"actorID":   claims.UserID,
+		"subjectID": subjectID,
+	})
+
+	return actingToken, actingClaims, nil
+}
+
+// actingScopes returns the permissions the actor holds on behalf of the subject and when they lapse.
+// Owners act for their dependents with every delegable scope; anyone else needs an active grant.
+func (s *authService) actingScopes(ctx context.Context, actorID, subjectID string) ([]string, *time.Time, error) {
+	dependent, err := s.delegationRepo.WithContext(ctx).GetDependentByID(subjectID)
+	if err == nil && dependent.OwnerID.String() == actorID {
+		return models.DelegableScopes, nil, nil
+	}
+	if err != nil && !goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errors.NewInternalError(err)
+	}
+
+	grant, err := s.delegationRepo.WithContext(ctx).GetActiveGrant(actorID, subjectID, time.Now())
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.NewAuthError("delegation.not_allowed")
+		}
+		return nil, nil, errors.NewInternalError(err)
+	}
+
+	return grant.ScopeList(), grant.ExpiresAt, nil
+}
+
+// signToken signs a JWT for the user with their current roles, permissions and licenses
+func (s *authService) signToken(ctx context.Context, user *models.User) (string, error) {
+	roles, permissions, err := s.resolveRoles(ctx, user.ID.String())
+	if err != nil {
+		return "", err
+	}
+
+	claims := utils.TokenClaims{
+		UserID:      user.ID.String(),
+		Role:        user.Role,
+		Roles:       roles,
+		Permissions: permissions,
+	}
+	if user.StoreID != nil {
+		claims.StoreID = *user.StoreID
+	}
+
+	// Only verified, unexpired licenses are vouched for in the token, which must not outlive them
+	var lastDay *time.Time
+	claims.LicensedIn, lastDay, err = s.credentialRepo.WithContext(ctx).GetLicensedProvinces(user.ID.String(), time.Now())
+	if err != nil {
+		return "", err
+	}
+	if lastDay != nil {
+		// Licenses are valid through their expiry date, in the server's time zone like the expiry job
+		lapsesAt := time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day()+1, 0, 0, 0, 0, time.Local)
+		if lapsesAt.Before(time.Now().Add(utils.TokenTTL)) {
+			claims.ExpiresAt = lapsesAt
+		}
+	}
+
+	// Only the reached age thresholds are published, never the date of birth
+	claims.AgeOver, err = s.eligibility.AgeOver(ctx, user.ID.String())
+	if err != nil {
+		return "", err
+	}
+
+	return s.sign(ctx, claims)
+}
+
+// sign signs the claims with the active signing key, falling back to the configured secret
+// when no key has been rotated in yet.
+func (s *authService) sign(ctx context.Context, claims utils.TokenClaims) (string, error) {
+	key, err := s.signingKeyRepo.WithContext(ctx).GetActiveKey()
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.GenerateJWT(claims, "", s.jwtSecret)
+		}
+		return "", err
+	}
+
+	return utils.GenerateJWT(claims, key.ID, key.Secret)
+}
+
+// resolveRoles returns the names of the user's roles and the sorted, de-duplicated permissions they grant
+func (s *authService) resolveRoles(ctx context.Context, userID string) ([]string, []string, error) {
+	roles, err := s.roleRepo.WithContext(ctx).GetUserRoles(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roleNames := make([]string, 0, len(roles))
+	seen := make(map[string]bool)
+	permissions := []string{}
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				permissions = append(permissions, permission.Name)
+			}
+		}
+	}
+	sort.Strings(permissions)
+
+	return roleNames, permissions, nil
+}
+
+// intersect returns the values of a that are also in b
+func intersect(a, b []string) []string {
+	allowed := make(map[string]bool, len(b))
+	for _, value := range b {
+		allowed[value] = true
+	}
+
+	result := []string{}
+	for _, value := range a {
+		if allowed[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// secretForKey returns the secret of the signing key with the given ID.
+// Tokens without a key ID were signed with the configured secret.
+func (s authService) secretForKey(ctx context.Context, kid string) (string, error) {
+	if kid == "" {
+		return s.jwtSecret, nil
+	}
+
+	key, err := s.signingKeyRepo.WithContext(ctx).GetKeyByID(kid)
 	if err != nil {
-		return "", "", errors.NewAuthError("Invalid token")
+		return "", err
 	}
 
-	return userID, role, nil
+	return key.Secret, nil
 }
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/policy"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type AuthorizationService interface {
+	Authorize(ctx context.Context, subjectToken, action, resource string, attributes map[string]string) (*policy.Decision, error)
+}
+
+type authorizationService struct {
+	authService  AuthService
+	policies     *policy.Store
+	decisionRepo repositories.AuthorizationDecisionRepository
+}
+
+func NewAuthorizationService(authService AuthService, policies *policy.Store, decisionRepo repositories.AuthorizationDecisionRepository) AuthorizationService {
+	return &authorizationService{
+		authService:  authService,
+		policies:     policies,
+		decisionRepo: decisionRepo,
+	}
+}
+
+func (s *authorizationService) Authorize(ctx context.Context, subjectToken, action, resource string, attributes map[string]string) (*policy.Decision, error) {
+	// Validate the request
+	validationErrors := make(map[string]string)
+	if strings.TrimSpace(action) == "" {
+		validationErrors["action"] = "authorization.action_required"
+	}
+	if strings.TrimSpace(resource) == "" {
+		validationErrors["resource"] = "authorization.resource_required"
+	}
+	if len(validationErrors) > 0 {
+		return nil, errors.NewValidationErrors(validationErrors)
+	}
+
+	claims, err := s.authService.VerifyToken(ctx, subjectToken)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := s.policies.Evaluate(policy.Request{
+		Subject:    subjectFromClaims(claims),
+		Action:     action,
+		Resource:   resource,
+		Attributes: attributes,
+	})
+
+	s.record(ctx, claims.UserID, action, resource, attributes, decision)
+
+	return &decision, nil
+}
+
+// record stores the decision for auditing. Failures are logged rather than returned
+// so that an unavailable audit table does not block authorization.
+func (s *authorizationService) record(ctx context.Context, userID, action, resource string, attributes map[string]string, decision policy.Decision) {
+	attributesJSON, err := json.Marshal(attributes)
+	if err != nil || attributes == nil {
+		attributesJSON = []byte("{}")
+	}
+
+	subjectID, err := uuid.Parse(userID)
+	if err != nil {
+		utils.Error("Failed to record authorization decision", map[string]interface{}{
+			"userID": userID,
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	entry := &models.AuthorizationDecision{
+		UserID:     subjectID,
+		Action:     action,
+		Resource:   resource,
+		Attributes: string(attributesJSON),
+		Allowed:    decision.Allowed,
+		Reason:     decision.Reason,
+	}
+	if decision.PolicyID != "" {
+		entry.PolicyID = &decision.PolicyID
+	}
+
+	if err := s.decisionRepo.WithContext(ctx).CreateDecision(entry); err != nil {
+		utils.Error("Failed to record authorization decision", map[string]interface{}{
+			"userID":   userID,
+			"action":   action,
+			"resource": resource,
+			"allowed":  decision.Allowed,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// subjectFromClaims exposes the token claims to policy conditions as subject attributes
+func subjectFromClaims(claims *utils.TokenClaims) policy.Subject {
+	attributes := map[string]string{
+		"user_id":  claims.UserID,
+		"role":     claims.Role,
+		"store_id": claims.StoreID,
+	}
+	// Acting tokens also expose who really acted
+	if claims.IsActing() {
+		attributes["actor_id"] = claims.Actor
+	}
+
+	return policy.Subject{
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		Attributes:  attributes,
+	}
+}
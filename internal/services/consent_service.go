@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ConsentInput is a policy version the user accepts or opts in to
+type ConsentInput struct {
+	Policy  string
+	Version string
+}
+
+var (
+	policyNamePattern    = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+	consentSourcePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+)
+
+type ConsentService interface {
+	ListPolicies(ctx context.Context) ([]models.PolicyDocument, error)
+	PublishPolicy(ctx context.Context, policy, version, title, url string, mandatory bool, effectiveAt string) (*models.PolicyDocument, error)
+	RecordConsent(ctx context.Context, userID, policy, version, source string) (*models.Consent, error)
+	WithdrawConsent(ctx context.Context, userID, policy, source string) (*models.Consent, error)
+	GetConsents(ctx context.Context, userID string) ([]models.Consent, []models.Consent, error)
+	RequiredConsents(ctx context.Context, userID string) ([]models.PolicyDocument, error)
+	CheckRegistrationConsents(ctx context.Context, consents []ConsentInput) error
+	RegistrationConsents(ctx context.Context, consents []ConsentInput) ([]models.Consent, error)
+}
+
+type consentService struct {
+	consentRepo repositories.ConsentRepository
+}
+
+func NewConsentService(consentRepo repositories.ConsentRepository) ConsentService {
+	return &consentService{
+		consentRepo: consentRepo,
+	}
+}
+
+// ListPolicies returns the current version of each policy
+func (s *consentService) ListPolicies(ctx context.Context) ([]models.PolicyDocument, error) {
+	policies, err := s.consentRepo.WithContext(ctx).GetCurrentPolicies(time.Now())
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return policies, nil
+}
+
+// PublishPolicy adds a policy version. When a new version of a mandatory policy takes effect,
+// users have to accept it again before Login reports their consent as complete.
+func (s *consentService) PublishPolicy(ctx context.Context, policy, version, title, url string, mandatory bool, effectiveAt string) (*models.PolicyDocument, error) {
+	validationErrors := make(map[string]string)
+	policy = strings.TrimSpace(policy)
+	version = strings.TrimSpace(version)
+	title = strings.TrimSpace(title)
+
+	if !policyNamePattern.MatchString(policy) {
+		validationErrors["policy"] = "consent.policy_invalid"
+	}
+	if version == "" || len(version) > 20 {
+		validationErrors["version"] = "consent.version_invalid"
+	}
+	if title == "" {
+		validationErrors["title"] = "consent.title_required"
+	}
+
+	effective := time.Now()
+	if effectiveAt != "" {
+		parsed, err := time.Parse(time.RFC3339, effectiveAt)
+		if err != nil {
+			validationErrors["effective_at"] = "consent.effective_at_invalid"
+		}
+		effective = parsed
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, errors.NewValidationErrors(validationErrors)
+	}
+
+	if _, err := s.consentRepo.WithContext(ctx).GetPolicy(policy, version); err == nil {
+		return nil, errors.NewConflictError("consent.version_exists").WithParams("version", version, "policy", policy)
+	} else if !goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NewInternalError(err)
+	}
+
+	document := &models.PolicyDocument{
+		Policy:      policy,
+		Version:     version,
+		Title:       title,
+		URL:         strings.TrimSpace(url),
+		Mandatory:   mandatory,
+		EffectiveAt: effective,
+	}
+	if err := s.consentRepo.WithContext(ctx).CreatePolicy(document); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Policy published", map[string]interface{}{
+		"policy":      policy,
+		"version":     version,
+		"mandatory":   mandatory,
+		"effectiveAt": effective.Format(time.RFC3339),
+	})
+
+	return document, nil
+}
+
+// RecordConsent records that the user accepts the given version of a policy, which must be the current one
+func (s *consentService) RecordConsent(ctx context.Context, userID, policy, version, source string) (*models.Consent, error) {
+	source, err := normalizeConsentSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	if version != current.Version {
+		return nil, errors.NewFieldError("version", i18n.M("consent.version_not_current", "policy", current.Policy, "version", current.Version))
+	}
+
+	return s.record(ctx, userID, current, true, source)
+}
+
+// WithdrawConsent records that the user no longer consents to a policy. Mandatory policies cannot be
+// withdrawn while the account is in use; closing the account is the way to stop agreeing to them.
+func (s *consentService) WithdrawConsent(ctx context.Context, userID, policy, source string) (*models.Consent, error) {
+	source, err := normalizeConsentSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	if current.Mandatory {
+		return nil, errors.NewBadRequestError("consent.required_to_use").WithParams("policy", current.Policy)
+	}
+
+	return s.record(ctx, userID, current, false, source)
+}
+
+// GetConsents returns the user's latest choice for each policy and the full ledger, newest first
+func (s *consentService) GetConsents(ctx context.Context, userID string) ([]models.Consent, []models.Consent, error) {
+	current, err := s.consentRepo.WithContext(ctx).GetLatestConsents(userID)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	history, err := s.consentRepo.WithContext(ctx).GetConsentsByUserID(userID)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	return current, history, nil
+}
+
+// RequiredConsents returns the current mandatory policies the user has not accepted
+func (s *consentService) RequiredConsents(ctx context.Context, userID string) ([]models.PolicyDocument, error) {
+	policies, err := s.consentRepo.WithContext(ctx).GetCurrentPolicies(time.Now())
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	latest, err := s.consentRepo.WithContext(ctx).GetLatestConsents(userID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	accepted := make(map[string]string, len(latest))
+	for _, consent := range latest {
+		if consent.Granted {
+			accepted[consent.Policy] = consent.Version
+		}
+	}
+
+	required := []models.PolicyDocument{}
+	for _, policy := range policies {
+		if policy.Mandatory && accepted[policy.Policy] != policy.Version {
+			required = append(required, policy)
+		}
+	}
+	return required, nil
+}
+
+// CheckRegistrationConsents checks that the consents given at registration name current policy versions
+// and include every mandatory policy. Clients that send no consents at all are accepted;
+// their users are asked to accept the mandatory policies when they first log in.
+func (s *consentService) CheckRegistrationConsents(ctx context.Context, consents []ConsentInput) error {
+	if len(consents) == 0 {
+		return nil
+	}
+
+	policies, err := s.consentRepo.WithContext(ctx).GetCurrentPolicies(time.Now())
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	current := make(map[string]models.PolicyDocument, len(policies))
+	for _, policy := range policies {
+		current[policy.Policy] = policy
+	}
+
+	validationErrors := make(map[string]i18n.Message)
+	given := make(map[string]bool, len(consents))
+	for i, consent := range consents {
+		field := fmt.Sprintf("consents[%d]", i)
+		policy, ok := current[consent.Policy]
+		switch {
+		case !ok:
+			validationErrors[field] = i18n.M("consent.unknown_policy", "policy", consent.Policy)
+		case consent.Version != policy.Version:
+			validationErrors[field] = i18n.M("consent.version_not_current", "policy", policy.Policy, "version", policy.Version)
+		default:
+			given[consent.Policy] = true
+		}
+	}
+
+	for _, policy := range policies {
+		if policy.Mandatory && !given[policy.Policy] {
+			validationErrors["consents."+policy.Policy] = i18n.M("consent.must_accept", "title", policy.Title, "version", policy.Version)
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
+	}
+	return nil
+}
+
+// RegistrationConsents returns the ledger entries of the consents checked by CheckRegistrationConsents,
+// to be recorded with the new account. Their user ID is set when the account is created.
+func (s *consentService) RegistrationConsents(ctx context.Context, consents []ConsentInput) ([]models.Consent, error) {
+	entries := make([]models.Consent, 0, len(consents))
+	for _, consent := range consents {
+		policy, err := s.consentRepo.WithContext(ctx).GetPolicy(consent.Policy, consent.Version)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		entries = append(entries, models.Consent{
+			PolicyID: policy.ID,
+			Policy:   policy.Policy,
+			Version:  policy.Version,
+			Granted:  true,
+			Source:   "registration",
+		})
+	}
+	return entries, nil
+}
+
+func (s *consentService) currentPolicy(ctx context.Context, policy string) (*models.PolicyDocument, error) {
+	if strings.TrimSpace(policy) == "" {
+		return nil, errors.NewValidationError("policy", "consent.policy_required")
+	}
+
+	policies, err := s.consentRepo.WithContext(ctx).GetCurrentPolicies(time.Now())
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	for i := range policies {
+		if policies[i].Policy == policy {
+			return &policies[i], nil
+		}
+	}
+	return nil, errors.NewNotFoundError("consent.policy_not_found").WithParams("policy", policy)
+}
+
+func (s *consentService) record(ctx context.Context, userID string, policy *models.PolicyDocument, granted bool, source string) (*models.Consent, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.NewAuthError("auth.invalid_user")
+	}
+
+	entries := []models.Consent{{
+		UserID:   id,
+		PolicyID: policy.ID,
+		Policy:   policy.Policy,
+		Version:  policy.Version,
+		Granted:  granted,
+		Source:   source,
+	}}
+	if err := s.consentRepo.WithContext(ctx).CreateConsents(entries); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Consent recorded", map[string]interface{}{
+		"userID":  userID,
+		"policy":  policy.Policy,
+		"version": policy.Version,
+		"granted": granted,
+		"source":  source,
+	})
+
+	return &entries[0], nil
+}
+
+// normalizeConsentSource defaults the source to "api" and checks it is a short identifier
+func normalizeConsentSource(source string) (string, error) {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if source == "" {
+		return "api", nil
+	}
+	if !consentSourcePattern.MatchString(source) {
+		return "", errors.NewValidationError("source", "consent.source_invalid")
+	}
+	return source, nil
+}
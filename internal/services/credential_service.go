@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CredentialService interface {
+	SubmitCredential(ctx context.Context, userID, licenseNumber, province, expiresOn string) (*models.ProfessionalCredential, error)
+	GetCredentials(ctx context.Context, userID string) ([]models.ProfessionalCredential, error)
+	ListCredentials(ctx context.Context, status string, pageSize int, pageToken string) ([]models.ProfessionalCredential, string, error)
+	ApproveCredential(ctx context.Context, reviewerID, credentialID string) (*models.ProfessionalCredential, error)
+	RejectCredential(ctx context.Context, reviewerID, credentialID, reason string) (*models.ProfessionalCredential, error)
+	ProcessExpirations(ctx context.Context, warningWindow time.Duration) error
+}
+
+type credentialService struct {
+	credentialRepo repositories.CredentialRepository
+	roleRepo       repositories.RoleRepository
+}
+
+func NewCredentialService(credentialRepo repositories.CredentialRepository, roleRepo repositories.RoleRepository) CredentialService {
+	return &credentialService{
+		credentialRepo: credentialRepo,
+		roleRepo:       roleRepo,
+	}
+}
+
+// SubmitCredential records a license for review. Only pharmacists can submit licenses.
+func (s *credentialService) SubmitCredential(ctx context.Context, userID, licenseNumber, province, expiresOn string) (*models.ProfessionalCredential, error) {
+	holder, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.NewAuthError("auth.invalid_user")
+	}
+
+	province = strings.ToUpper(strings.TrimSpace(province))
+	if err := utils.ValidateCredentialInput(licenseNumber, province, expiresOn); err != nil {
+		return nil, err
+	}
+
+	isPharmacist, err := s.hasRole(ctx, userID, "pharmacist")
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if !isPharmacist {
+		return nil, errors.NewAuthError("credential.pharmacists_only")
+	}
+
+	// A license already under review for the province must be decided first
+	credentials, err := s.credentialRepo.WithContext(ctx).GetCredentialsByUserID(userID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	for _, credential := range credentials {
+		if credential.IssuingProvince == province && credential.Status == models.CredentialPending {
+			return nil, errors.NewConflictError("credential.already_pending")
+		}
+	}
+
+	expiry, _ := utils.ParseDate(expiresOn)
+	credential := &models.ProfessionalCredential{
+		UserID:          holder,
+		LicenseNumber:   strings.ToUpper(licenseNumber),
+		IssuingProvince: province,
+		ExpiresOn:       expiry,
+		Status:          models.CredentialPending,
+	}
+	if err := s.credentialRepo.WithContext(ctx).CreateCredential(credential); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Credential submitted", map[string]interface{}{
+		"credentialID": credential.ID.String(),
+		"userID":       userID,
+		"province":     province,
+	})
+
+	return credential, nil
+}
+
+func (s *credentialService) GetCredentials(ctx context.Context, userID string) ([]models.ProfessionalCredential, error) {
+	credentials, err := s.credentialRepo.WithContext(ctx).GetCredentialsByUserID(userID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return credentials, nil
+}
+
+func (s *credentialService) ListCredentials(ctx context.Context, status string, pageSize int, pageToken string) ([]models.ProfessionalCredential, string, error) {
+	switch status {
+	case "", models.CredentialPending, models.CredentialVerified, models.CredentialRejected, models.CredentialExpired:
+	default:
+		return nil, "", errors.NewValidationError("status", "credential.status_invalid")
+	}
+
+	switch {
+	case pageSize < 0:
+		return nil, "", errors.NewValidationError("page_size", "page.size_negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	offset := 0
+	if pageToken != "" {
+		var err error
+		offset, err = strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return nil, "", errors.NewValidationError("page_token", "page.invalid_token")
+		}
+	}
+
+	credentials, err := s.credentialRepo.WithContext(ctx).ListCredentials(status, pageSize+1, offset)
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	nextPageToken := ""
+	if len(credentials) > pageSize {
+		credentials = credentials[:pageSize]
+		nextPageToken = strconv.Itoa(offset + pageSize)
+	}
+
+	return credentials, nextPageToken, nil
+}
+
+func (s *credentialService) ApproveCredential(ctx context.Context, reviewerID, credentialID string) (*models.ProfessionalCredential, error) {
+	credential, err := s.getPendingCredential(ctx, reviewerID, credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !credential.ExpiresOn.AddDate(0, 0, 1).After(time.Now()) {
+		return nil, errors.NewBadRequestError("validation.license_expired")
+	}
+
+	if err := s.review(ctx, credential, reviewerID, models.CredentialVerified, nil); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+func (s *credentialService) RejectCredential(ctx context.Context, reviewerID, credentialID, reason string) (*models.ProfessionalCredential, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, errors.NewValidationError("reason", "credential.reason_required")
+	}
+
+	credential, err := s.getPendingCredential(ctx, reviewerID, credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.review(ctx, credential, reviewerID, models.CredentialRejected, &reason); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// ProcessExpirations flags verified licenses that expire within the warning window
+// and downgrades licenses whose expiry date has passed
+func (s *credentialService) ProcessExpirations(ctx context.Context, warningWindow time.Duration) error {
+	now := time.Now()
+
+	flagged, err := s.credentialRepo.WithContext(ctx).FlagExpiring(now.Add(warningWindow))
+	if err != nil {
+		return err
+	}
+	for _, credential := range flagged {
+		utils.Warn("License expiring soon", map[string]interface{}{
+			"credentialID": credential.ID.String(),
+			"userID":       credential.UserID.String(),
+			"province":     credential.IssuingProvince,
+			"expiresOn":    credential.ExpiresOn.Format("2006-01-02"),
+		})
+	}
+
+	expired, err := s.credentialRepo.WithContext(ctx).ExpireCredentials(now)
+	if err != nil {
+		return err
+	}
+	for _, credential := range expired {
+		utils.Warn("License expired", map[string]interface{}{
+			"credentialID": credential.ID.String(),
+			"userID":       credential.UserID.String(),
+			"province":     credential.IssuingProvince,
+		})
+	}
+
+	return nil
+}
+
+func (s *credentialService) getPendingCredential(ctx context.Context, reviewerID, credentialID string) (*models.ProfessionalCredential, error) {
+	if _, err := uuid.Parse(credentialID); err != nil {
+		return nil, errors.NewValidationError("credential_id", "credential.invalid_id")
+	}
+
+	credential, err := s.credentialRepo.WithContext(ctx).GetCredentialByID(credentialID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("credential.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	if credential.UserID.String() == reviewerID {
+		return nil, errors.NewBadRequestError("credential.own_review")
+	}
+
+	if credential.Status != models.CredentialPending {
+		return nil, errors.NewConflictError("credential.already_reviewed")
+	}
+
+	return credential, nil
+}
+
+func (s *credentialService) review(ctx context.Context, credential *models.ProfessionalCredential, reviewerID, status string, reason *string) error {
+	reviewer, err := uuid.Parse(reviewerID)
+	if err != nil {
+		return errors.NewAuthError("credential.invalid_reviewer")
+	}
+
+	now := time.Now()
+	credential.Status = status
+	credential.ReviewedBy = &reviewer
+	credential.ReviewedAt = &now
+	credential.RejectionReason = reason
+	if err := s.credentialRepo.WithContext(ctx).UpdateCredential(credential); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Credential reviewed", map[string]interface{}{
+		"credentialID": credential.ID.String(),
+		"userID":       credential.UserID.String(),
+		"status":       status,
+		"reviewerID":   reviewerID,
+	})
+
+	return nil
+}
+
+func (s *credentialService) hasRole(ctx context.Context, userID, roleName string) (bool, error) {
+	roles, err := s.roleRepo.WithContext(ctx).GetUserRoles(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.Name == roleName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
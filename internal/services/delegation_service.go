@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DelegationService interface {
+	CreateDependent(ctx context.Context, ownerID, firstName, lastName, dob string) (*models.Dependent, error)
+	GetDependents(ctx context.Context, ownerID string) ([]models.Dependent, error)
+	GrantDelegation(ctx context.Context, grantorID, delegate, subjectID string, scopes []string, expiresAt string) (*models.DelegationGrant, error)
+	RevokeDelegation(ctx context.Context, userID, grantID string) (*models.DelegationGrant, error)
+	GetDelegations(ctx context.Context, userID string) ([]models.DelegationGrant, error)
+}
+
+type delegationService struct {
+	delegationRepo repositories.DelegationRepository
+	authService    AuthService
+	audit          AuditService
+}
+
+func NewDelegationService(delegationRepo repositories.DelegationRepository, authService AuthService, auditService AuditService) DelegationService {
+	return &delegationService{
+		delegationRepo: delegationRepo,
+		authService:    authService,
+		audit:          auditService,
+	}
+}
+
+// CreateDependent adds a dependent managed by the owner
+func (s *delegationService) CreateDependent(ctx context.Context, ownerID, firstName, lastName, dob string) (*models.Dependent, error) {
+	owner, err := uuid.Parse(ownerID)
+	if err != nil {
+		return nil, errors.NewAuthError("auth.invalid_user")
+	}
+
+	if err := utils.ValidateDependentInput(firstName, lastName, dob); err != nil {
+		return nil, err
+	}
+
+	dependent := &models.Dependent{
+		OwnerID:   owner,
+		FirstName: strings.TrimSpace(firstName),
+		LastName:  strings.TrimSpace(lastName),
+	}
+	if strings.TrimSpace(dob) != "" {
+		dobTime, _ := utils.ParseDOB(dob)
+		dependent.DateOfBirth = &dobTime
+	}
+
+	if err := s.delegationRepo.WithContext(ctx).CreateDependent(dependent); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Dependent created", map[string]interface{}{
+		"dependentID": dependent.ID.String(),
+		"ownerID":     ownerID,
+	})
+
+	return dependent, nil
+}
+
+func (s *delegationService) GetDependents(ctx context.Context, ownerID string) ([]models.Dependent, error) {
+	dependents, err := s.delegationRepo.WithContext(ctx).GetDependentsByOwnerID(ownerID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return dependents, nil
+}
+
+// GrantDelegation lets the delegate, identified by ID, email or username, act on behalf of the subject.
+// The subject is either the grantor, when subjectID is empty, or one of the grantor's dependents.
+func (s *delegationService) GrantDelegation(ctx context.Context, grantorID, delegate, subjectID string, scopes []string, expiresAt string) (*models.DelegationGrant, error) {
+	grantor, err := uuid.Parse(grantorID)
+	if err != nil {
+		return nil, errors.NewAuthError("auth.invalid_user")
+	}
+
+	scopes = normalizeScopes(scopes)
+	if err := utils.ValidateDelegationInput(scopes, models.DelegableScopes, expiresAt); err != nil {
+		return nil, err
+	}
+
+	// Resolve the subject
+	subject := grantor
+	subjectType := models.SubjectUser
+	if subjectID != "" && subjectID != grantorID {
+		dependent, err := s.delegationRepo.WithContext(ctx).GetDependentByID(subjectID)
+		if err != nil {
+			if goerrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.NewNotFoundError("delegation.dependent_not_found")
+			}
+			return nil, errors.NewInternalError(err)
+		}
+		if dependent.OwnerID != grantor {
+			return nil, errors.NewAuthError("delegation.not_dependent")
+		}
+		subject = dependent.ID
+		subjectType = models.SubjectDependent
+	}
+
+	// Resolve the delegate
+	if strings.TrimSpace(delegate) == "" {
+		return nil, errors.NewValidationError("delegate", "delegation.delegate_required")
+	}
+	delegateUser, err := s.authService.FindUser(ctx, delegate)
+	if err != nil {
+		return nil, err
+	}
+	if delegateUser.DisabledAt != nil {
+		return nil, errors.NewValidationError("delegate", "delegation.delegate_disabled")
+	}
+	if delegateUser.ID == grantor {
+		return nil, errors.NewValidationError("delegate", "delegation.self")
+	}
+
+	grant := &models.DelegationGrant{
+		DelegateID:  delegateUser.ID,
+		SubjectID:   subject,
+		SubjectType: subjectType,
+		Scopes:      strings.Join(scopes, " "),
+		GrantedBy:   grantor,
+	}
+	if expiresAt != "" {
+		expiry, _ := time.Parse(time.RFC3339, expiresAt)
+		grant.ExpiresAt = &expiry
+	}
+
+	if err := s.delegationRepo.WithContext(ctx).CreateGrant(grant); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Delegation granted", map[string]interface{}{
+		"grantID":    grant.ID.String(),
+		"grantedBy":  grantorID,
+		"delegateID": delegateUser.ID.String(),
+		"subjectID":  subject.String(),
+		"scopes":     grant.Scopes,
+	})
+	s.audit.Record(ctx, "delegation.granted", grantorID, delegateUser.ID.String(), map[string]interface{}{
+		"grant_id":     grant.ID.String(),
+		"subject_type": grant.SubjectType,
+		"scopes":       grant.ScopeList(),
+	})
+
+	return grant, nil
+}
+
+// RevokeDelegation ends a grant. The grantor, the delegate and a user subject can revoke it.
+func (s *delegationService) RevokeDelegation(ctx context.Context, userID, grantID string) (*models.DelegationGrant, error) {
+	if _, err := uuid.Parse(grantID); err != nil {
+		return nil, errors.NewValidationError("grant_id", "delegation.invalid_grant_id")
+	}
+
+	grant, err := s.delegationRepo.WithContext(ctx).GetGrantByID(grantID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("delegation.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	isParty := grant.GrantedBy.String() == userID ||
+		grant.DelegateID.String() == userID ||
+		(grant.SubjectType == models.SubjectUser && grant.SubjectID.String() == userID)
+	if !isParty {
+		return nil, errors.NewNotFoundError("delegation.not_found")
+	}
+
+	if grant.RevokedAt != nil {
+		return nil, errors.NewConflictError("delegation.already_revoked")
+	}
+
+	now := time.Now()
+	grant.RevokedAt = &now
+	if err := s.delegationRepo.WithContext(ctx).UpdateGrant(grant); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Delegation revoked", map[string]interface{}{
+		"grantID":   grantID,
+		"revokedBy": userID,
+	})
+	// Acting tokens issued under the grant stop working with it
+	s.audit.Record(ctx, "delegation.revoked", userID, grant.DelegateID.String(), map[string]interface{}{
+		"grant_id":       grantID,
+		"tokens_revoked": true,
+	})
+
+	return grant, nil
+}
+
+// GetDelegations returns the grants the user has given, received or is the subject of
+func (s *delegationService) GetDelegations(ctx context.Context, userID string) ([]models.DelegationGrant, error) {
+	grants, err := s.delegationRepo.WithContext(ctx).GetGrantsByUserID(userID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return grants, nil
+}
+
+// normalizeScopes trims, de-duplicates and sorts scopes
+func normalizeScopes(scopes []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope != "" && !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
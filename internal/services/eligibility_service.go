@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/eligibility"
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EligibilityResult is the outcome of an age check. It never carries the date of birth.
+type EligibilityResult struct {
+	Eligible    bool
+	RequiredAge int
+	Reason      string
+}
+
+type EligibilityService interface {
+	AgeOver(ctx context.Context, subjectID string) ([]int, error)
+	CheckEligibility(ctx context.Context, caller *utils.TokenClaims, subjectID, rule string) (*EligibilityResult, error)
+}
+
+type eligibilityService struct {
+	customerRepo   repositories.CustomerRepository
+	delegationRepo repositories.DelegationRepository
+	addressRepo    repositories.AddressRepository
+	rules          *eligibility.Rules
+}
+
+func NewEligibilityService(customerRepo repositories.CustomerRepository, delegationRepo repositories.DelegationRepository, addressRepo repositories.AddressRepository, rules *eligibility.Rules) EligibilityService {
+	return &eligibilityService{
+		customerRepo:   customerRepo,
+		delegationRepo: delegationRepo,
+		addressRepo:    addressRepo,
+		rules:          rules,
+	}
+}
+
+// AgeOver returns the age thresholds of the subject's province that the subject has reached.
+// Subjects without a known date of birth, such as staff, have none.
+func (s *eligibilityService) AgeOver(ctx context.Context, subjectID string) ([]int, error) {
+	dob, province, err := s.profile(ctx, subjectID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if dob == nil {
+		return nil, nil
+	}
+
+	return s.rules.AgeOver(eligibility.Age(*dob, time.Now()), province), nil
+}
+
+// CheckEligibility reports whether the user or dependent meets the age restriction of the rule in their province.
+// The caller may only ask about themselves or a dependent they own, unless they hold the eligibility:check permission.
+func (s *eligibilityService) CheckEligibility(ctx context.Context, caller *utils.TokenClaims, subjectID, rule string) (*EligibilityResult, error) {
+	if _, err := uuid.Parse(subjectID); err != nil {
+		return nil, errors.NewValidationError("user_id", "user.invalid_id")
+	}
+	if err := s.authorize(ctx, caller, subjectID); err != nil {
+		return nil, err
+	}
+	if !s.rules.HasRule(rule) {
+		return nil, errors.NewNotFoundError("eligibility.rule_not_found").WithParams("rule", rule)
+	}
+
+	dob, province, err := s.profile(ctx, subjectID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("user.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	requiredAge, ok := s.rules.MinAge(rule, province)
+	if !ok {
+		return &EligibilityResult{Eligible: false, Reason: fmt.Sprintf("Not available in %s", province)}, nil
+	}
+
+	if dob == nil {
+		return &EligibilityResult{Eligible: false, RequiredAge: requiredAge, Reason: "Date of birth is unknown"}, nil
+	}
+
+	if eligibility.Age(*dob, time.Now()) < requiredAge {
+		return &EligibilityResult{Eligible: false, RequiredAge: requiredAge, Reason: fmt.Sprintf("Must be at least %d years old", requiredAge)}, nil
+	}
+
+	return &EligibilityResult{Eligible: true, RequiredAge: requiredAge}, nil
+}
+
+// authorize checks that the caller may learn the eligibility of the subject
+func (s *eligibilityService) authorize(ctx context.Context, caller *utils.TokenClaims, subjectID string) error {
+	if caller.UserID == subjectID {
+		return nil
+	}
+	for _, permission := range caller.Permissions {
+		if permission == "eligibility:check" {
+			return nil
+		}
+	}
+
+	// Acting tokens are limited to their subject
+	if !caller.IsActing() {
+		dependent, err := s.delegationRepo.WithContext(ctx).GetDependentByID(subjectID)
+		if err == nil && dependent.OwnerID.String() == caller.UserID {
+			return nil
+		}
+		if err != nil && !goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewInternalError(err)
+		}
+	}
+
+	return errors.NewAuthError("eligibility.not_allowed")
+}
+
+// profile returns the date of birth and province of a customer or dependent.
+// Customers live in the province of their default billing address, dependents in the province of their owner.
+func (s *eligibilityService) profile(ctx context.Context, subjectID string) (*time.Time, string, error) {
+	customer, err := s.customerRepo.WithContext(ctx).GetCustomerByUserID(subjectID)
+	if err == nil {
+		province, err := s.province(ctx, customer)
+		return customer.DateOfBirth, province, err
+	}
+	if !goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", err
+	}
+
+	dependent, err := s.delegationRepo.WithContext(ctx).GetDependentByID(subjectID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	owner, err := s.customerRepo.WithContext(ctx).GetCustomerByUserID(dependent.OwnerID.String())
+	if err != nil {
+		return nil, "", err
+	}
+
+	province, err := s.province(ctx, owner)
+	return dependent.DateOfBirth, province, err
+}
+
+// province returns the province of the customer's default billing address, or "" when they have none
+func (s *eligibilityService) province(ctx context.Context, customer *models.Customer) (string, error) {
+	address, err := s.addressRepo.WithContext(ctx).GetDefaultBillingAddress(customer.ID.String())
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return normalizeProvince(address.Province), nil
+}
+
+// normalizeProvince returns the province code, also for addresses stored before provinces were normalized
+func normalizeProvince(province string) string {
+	if code, ok := utils.NormalizeProvince(province); ok {
+		return code
+	}
+	return strings.ToUpper(strings.TrimSpace(province))
+}
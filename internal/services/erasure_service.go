@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErasureService closes accounts and erases their personal information.
+// Self-service deletions and admin erasures go through the same pipeline.
+type ErasureService interface {
+	RequestDeletion(ctx context.Context, userID, password string) (*models.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID string) error
+	EraseNow(ctx context.Context, actorID, userID string) error
+	ProcessDueDeletions(ctx context.Context) error
+}
+
+type erasureService struct {
+	userRepo     repositories.UserRepository
+	deletionRepo repositories.AccountDeletionRepository
+	audit        AuditService
+	gracePeriod  time.Duration
+}
+
+func NewErasureService(userRepo repositories.UserRepository, deletionRepo repositories.AccountDeletionRepository, auditService AuditService, gracePeriod time.Duration) ErasureService {
+	return &erasureService{
+		userRepo:     userRepo,
+		deletionRepo: deletionRepo,
+		audit:        auditService,
+		gracePeriod:  gracePeriod,
+	}
+}
+
+// RequestDeletion schedules the erasure of the user's account after the grace period.
+// The user has to confirm their password.
+func (s *erasureService) RequestDeletion(ctx context.Context, userID, password string) (*models.AccountDeletion, error) {
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("user.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	if password == "" {
+		return nil, errors.NewValidationError("password", "validation.password_required")
+	}
+	if err := utils.CheckPasswordHash(ctx, password, user.PasswordHash); err != nil {
+		return nil, errors.NewAuthError("auth.incorrect_password")
+	}
+
+	if _, err := s.deletionRepo.WithContext(ctx).GetPendingDeletion(userID); err == nil {
+		return nil, errors.NewConflictError("erasure.already_scheduled")
+	} else if !goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NewInternalError(err)
+	}
+
+	deletion := &models.AccountDeletion{
+		UserID:       user.ID,
+		RequestedBy:  user.ID,
+		ScheduledFor: time.Now().Add(s.gracePeriod),
+	}
+	if err := s.deletionRepo.WithContext(ctx).CreateDeletion(deletion); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	s.audit.Record(ctx, "account.deletion_requested", user.ID.String(), user.ID.String(), map[string]interface{}{
+		"deletion_id":   deletion.ID.String(),
+		"scheduled_for": deletion.ScheduledFor.Format(time.RFC3339),
+	})
+
+	return deletion, nil
+}
+
+// CancelDeletion cancels the user's scheduled account deletion
+func (s *erasureService) CancelDeletion(ctx context.Context, userID string) error {
+	deletion, err := s.deletionRepo.WithContext(ctx).GetPendingDeletion(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("erasure.not_scheduled")
+		}
+		return errors.NewInternalError(err)
+	}
+
+	now := time.Now()
+	deletion.CancelledAt = &now
+	if err := s.deletionRepo.WithContext(ctx).UpdateDeletion(deletion); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	s.audit.Record(ctx, "account.deletion_cancelled", deletion.UserID.String(), deletion.UserID.String(), map[string]interface{}{
+		"deletion_id": deletion.ID.String(),
+	})
+
+	return nil
+}
+
+// EraseNow erases the user's account without a grace period, e.g. when an admin deletes a user
+func (s *erasureService) EraseNow(ctx context.Context, actorID, userID string) error {
+	actor, err := uuid.Parse(actorID)
+	if err != nil {
+		return errors.NewAuthError("auth.invalid_user")
+	}
+
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("user.not_found")
+		}
+		return errors.NewInternalError(err)
+	}
+	if user.ErasedAt != nil {
+		return errors.NewConflictError("erasure.already_erased")
+	}
+
+	// Take over a pending self-service request rather than leaving it to run again
+	deletion, err := s.deletionRepo.WithContext(ctx).GetPendingDeletion(userID)
+	if goerrors.Is(err, gorm.ErrRecordNotFound) {
+		deletion = &models.AccountDeletion{UserID: user.ID, RequestedBy: actor, ScheduledFor: time.Now()}
+		err = s.deletionRepo.WithContext(ctx).CreateDeletion(deletion)
+	}
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	if err := s.erase(ctx, deletion); err != nil {
+		return errors.NewInternalError(err)
+	}
+	return nil
+}
+
+// ProcessDueDeletions erases the accounts whose grace period has ended
+func (s *erasureService) ProcessDueDeletions(ctx context.Context) error {
+	deletions, err := s.deletionRepo.WithContext(ctx).GetDueDeletions(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range deletions {
+		if err := s.erase(ctx, &deletions[i]); err != nil {
+			utils.Error("Failed to erase account", map[string]interface{}{
+				"deletionID": deletions[i].ID.String(),
+				"error":      err.Error(),
+			})
+		}
+	}
+	return nil
+}
+
+// erase anonymizes the account, which also makes its tokens invalid, and completes the deletion request
+func (s *erasureService) erase(ctx context.Context, deletion *models.AccountDeletion) error {
+	now := time.Now()
+	deleted := models.NewOutboxEvent(models.EventUserDeleted, deletion.UserID, map[string]interface{}{
+		"self_requested": deletion.RequestedBy == deletion.UserID,
+	})
+	if err := s.userRepo.WithContext(ctx).EraseUser(deletion.UserID.String(), deletion.ID, now, deleted); err != nil {
+		return err
+	}
+	deletion.CompletedAt = &now
+
+	s.audit.Record(ctx, "account.erased", deletion.RequestedBy.String(), deletion.UserID.String(), map[string]interface{}{
+		"deletion_id":    deletion.ID.String(),
+		"self_requested": deletion.RequestedBy == deletion.UserID,
+	})
+
+	utils.Info("Account erased", map[string]interface{}{
+		"userID":     deletion.UserID.String(),
+		"deletionID": deletion.ID.String(),
+	})
+
+	return nil
+}
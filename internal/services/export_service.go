@@ -0,0 +1,477 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportService prepares documents with the personal information held about a user.
+// Exports run in the background; the caller polls the job and downloads it with the token it was given.
+type ExportService interface {
+	ExportMyData(ctx context.Context, userID, format string) (*models.DataExport, string, error)
+	GetDataExport(ctx context.Context, userID, exportID string) (*models.DataExport, error)
+	DownloadDataExport(ctx context.Context, userID, token string) (*models.DataExport, error)
+	ProcessPendingExports(ctx context.Context) error
+}
+
+type exportService struct {
+	exportRepo     repositories.DataExportRepository
+	userRepo       repositories.UserRepository
+	customerRepo   repositories.CustomerRepository
+	addressRepo    repositories.AddressRepository
+	roleRepo       repositories.RoleRepository
+	delegationRepo repositories.DelegationRepository
+	credentialRepo repositories.CredentialRepository
+	deletionRepo   repositories.AccountDeletionRepository
+	auditRepo      repositories.AuditRepository
+	consentRepo    repositories.ConsentRepository
+	ttl            time.Duration
+}
+
+func NewExportService(exportRepo repositories.DataExportRepository, userRepo repositories.UserRepository, customerRepo repositories.CustomerRepository, addressRepo repositories.AddressRepository, roleRepo repositories.RoleRepository, delegationRepo repositories.DelegationRepository, credentialRepo repositories.CredentialRepository, deletionRepo repositories.AccountDeletionRepository, auditRepo repositories.AuditRepository, consentRepo repositories.ConsentRepository, ttl time.Duration) ExportService {
+	return &exportService{
+		exportRepo:     exportRepo,
+		userRepo:       userRepo,
+		customerRepo:   customerRepo,
+		addressRepo:    addressRepo,
+		roleRepo:       roleRepo,
+		delegationRepo: delegationRepo,
+		credentialRepo: credentialRepo,
+		deletionRepo:   deletionRepo,
+		auditRepo:      auditRepo,
+		consentRepo:    consentRepo,
+		ttl:            ttl,
+	}
+}
+
+// ExportMyData starts an export of the user's data in the given format, json or zip.
+// It returns the job and the download token, which is only shown once.
+func (s *exportService) ExportMyData(ctx context.Context, userID, format string) (*models.DataExport, string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = models.ExportFormatJSON
+	case models.ExportFormatJSON, models.ExportFormatZip:
+	default:
+		return nil, "", errors.NewValidationError("format", "export.format_invalid")
+	}
+
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", errors.NewNotFoundError("user.not_found")
+		}
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	// One export at a time per user
+	if _, err := s.exportRepo.WithContext(ctx).GetActiveExport(userID); err == nil {
+		return nil, "", errors.NewConflictError("export.in_progress")
+	} else if !goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	token, err := utils.GenerateRandomHex(32)
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	export := &models.DataExport{
+		UserID:    user.ID,
+		Format:    format,
+		Status:    models.ExportPending,
+		TokenHash: utils.HashToken(token),
+	}
+	if err := s.exportRepo.WithContext(ctx).CreateExport(export); err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	utils.Info("Data export requested", map[string]interface{}{
+		"userID":   userID,
+		"exportID": export.ID.String(),
+		"format":   format,
+	})
+
+	// Start right away; the periodic job picks the export up if this instance stops first.
+	// The export outlives the call, so it keeps the trace but not the call's cancellation.
+	go s.runExport(context.WithoutCancel(ctx), *export)
+
+	return export, token, nil
+}
+
+// GetDataExport returns the status of one of the user's exports
+func (s *exportService) GetDataExport(ctx context.Context, userID, exportID string) (*models.DataExport, error) {
+	if _, err := uuid.Parse(exportID); err != nil {
+		return nil, errors.NewValidationError("export_id", "export.invalid_id")
+	}
+
+	export, err := s.exportRepo.WithContext(ctx).GetExportByID(exportID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("export.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	// Other users' exports are reported as missing rather than forbidden
+	if export.UserID.String() != userID {
+		return nil, errors.NewNotFoundError("export.not_found")
+	}
+	return export, nil
+}
+
+// DownloadDataExport returns the completed export the token was issued for, including its document
+func (s *exportService) DownloadDataExport(ctx context.Context, userID, token string) (*models.DataExport, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, errors.NewValidationError("download_token", "export.token_required")
+	}
+
+	export, err := s.exportRepo.WithContext(ctx).GetExportByTokenHash(utils.HashToken(token))
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("export.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	if export.UserID.String() != userID {
+		return nil, errors.NewNotFoundError("export.not_found")
+	}
+
+	switch export.Status {
+	case models.ExportPending, models.ExportRunning:
+		return nil, errors.NewConflictError("export.not_ready")
+	case models.ExportFailed:
+		return nil, errors.NewBadRequestError("export.failed")
+	}
+	if export.ExpiresAt == nil || !time.Now().Before(*export.ExpiresAt) {
+		return nil, errors.NewAuthError("export.token_expired")
+	}
+
+	return export, nil
+}
+
+// ProcessPendingExports deletes expired exports and runs the ones that have not been started
+func (s *exportService) ProcessPendingExports(ctx context.Context) error {
+	deleted, err := s.exportRepo.WithContext(ctx).DeleteExpiredExports(time.Now())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		utils.Info("Expired data exports deleted", map[string]interface{}{
+			"count": deleted,
+		})
+	}
+
+	exports, err := s.exportRepo.WithContext(ctx).GetPendingExports()
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		s.runExport(ctx, export)
+	}
+	return nil
+}
+
+// runExport builds the document of a pending export, unless another worker already claimed it
+func (s *exportService) runExport(ctx context.Context, export models.DataExport) {
+	claimed, err := s.exportRepo.WithContext(ctx).ClaimExport(export.ID.String())
+	if err != nil || !claimed {
+		if err != nil {
+			utils.Error("Failed to claim data export", map[string]interface{}{
+				"exportID": export.ID.String(),
+				"error":    err.Error(),
+			})
+		}
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+
+	data, err := s.buildExport(ctx, export.UserID.String(), export.Format)
+	if err != nil {
+		// The reason is logged, the stored message stays free of details
+		utils.Error("Failed to build data export", map[string]interface{}{
+			"exportID": export.ID.String(),
+			"error":    err.Error(),
+		})
+		reason := "The export could not be prepared"
+		export.Status = models.ExportFailed
+		export.Error = &reason
+	} else {
+		export.Status = models.ExportCompleted
+		export.Data = data
+	}
+
+	if err := s.exportRepo.WithContext(ctx).UpdateExport(&export); err != nil {
+		utils.Error("Failed to save data export", map[string]interface{}{
+			"exportID": export.ID.String(),
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	utils.Info("Data export finished", map[string]interface{}{
+		"exportID": export.ID.String(),
+		"status":   export.Status,
+		"bytes":    len(export.Data),
+	})
+}
+
+// exportSection is one part of an export: a key in the JSON document and a file in the zip archive
+type exportSection struct {
+	Name string
+	Data interface{}
+}
+
+// buildExport gathers the user's data and encodes it in the format
+func (s *exportService) buildExport(ctx context.Context, userID, format string) ([]byte, error) {
+	sections, err := s.gatherSections(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == models.ExportFormatZip {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for _, section := range sections {
+			file, err := archive.Create(section.Name + ".json")
+			if err != nil {
+				return nil, err
+			}
+			encoder := json.NewEncoder(file)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(section.Data); err != nil {
+				return nil, err
+			}
+		}
+		if err := archive.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	document := make(map[string]interface{}, len(sections))
+	for _, section := range sections {
+		document[section.Name] = section.Data
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// gatherSections collects everything stored about the user. Access tokens are stateless and not stored,
+// so sign-ins are covered by the audit events rather than a list of sessions.
+func (s *exportService) gatherSections(ctx context.Context, userID string) ([]exportSection, error) {
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.WithContext(ctx).GetRoleNamesByUserIDs([]uuid.UUID{user.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []exportSection{
+		{Name: "export", Data: map[string]interface{}{
+			"user_id":      user.ID,
+			"generated_at": time.Now().UTC().Format(time.RFC3339),
+		}},
+		{Name: "account", Data: map[string]interface{}{
+			"id":          user.ID,
+			"username":    user.Username,
+			"email":       user.Email,
+			"role":        user.Role,
+			"roles":       roles[user.ID],
+			"store_id":    user.StoreID,
+			"disabled_at": user.DisabledAt,
+			"created_at":  user.CreatedAt,
+		}},
+	}
+
+	// Staff accounts have no customer profile
+	customer, err := s.customerRepo.WithContext(ctx).GetCustomerByUserID(userID)
+	switch {
+	case err == nil:
+		addresses, err := s.addressRepo.WithContext(ctx).GetAddressesByCustomerID(customer.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections,
+			exportSection{Name: "profile", Data: map[string]interface{}{
+				"first_name":    customer.FirstName,
+				"last_name":     customer.LastName,
+				"phone":         customer.Phone,
+				"date_of_birth": customer.DateOfBirth,
+				"created_at":    customer.CreatedAt,
+				"updated_at":    customer.UpdatedAt,
+			}},
+			exportSection{Name: "addresses", Data: exportAddresses(addresses)},
+		)
+	case !goerrors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	dependents, err := s.delegationRepo.WithContext(ctx).GetDependentsByOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.delegationRepo.WithContext(ctx).GetGrantsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := s.credentialRepo.WithContext(ctx).GetCredentialsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	deletions, err := s.deletionRepo.WithContext(ctx).GetDeletionsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	consents, err := s.consentRepo.WithContext(ctx).GetConsentsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.auditRepo.WithContext(ctx).GetEventsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(sections,
+		exportSection{Name: "dependents", Data: exportDependents(dependents)},
+		exportSection{Name: "delegations", Data: exportGrants(grants)},
+		exportSection{Name: "credentials", Data: exportCredentials(credentials)},
+		exportSection{Name: "consents", Data: exportConsents(consents)},
+		exportSection{Name: "account_deletions", Data: exportDeletions(deletions)},
+		exportSection{Name: "audit_events", Data: exportAuditEvents(events)},
+	), nil
+}
+
+func exportAddresses(addresses []models.Address) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(addresses))
+	for _, address := range addresses {
+		rows = append(rows, map[string]interface{}{
+			"id":                  address.ID,
+			"label":               address.Label,
+			"street_line1":        address.StreetLine1,
+			"street_line2":        address.StreetLine2,
+			"city":                address.City,
+			"province":            address.Province,
+			"postal_code":         address.PostalCode,
+			"country":             address.Country,
+			"is_default_shipping": address.IsDefaultShipping,
+			"is_default_billing":  address.IsDefaultBilling,
+			"created_at":          address.CreatedAt,
+			"updated_at":          address.UpdatedAt,
+		})
+	}
+	return rows
+}
+
+func exportDependents(dependents []models.Dependent) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(dependents))
+	for _, dependent := range dependents {
+		rows = append(rows, map[string]interface{}{
+			"id":            dependent.ID,
+			"first_name":    dependent.FirstName,
+			"last_name":     dependent.LastName,
+			"date_of_birth": dependent.DateOfBirth,
+			"created_at":    dependent.CreatedAt,
+		})
+	}
+	return rows
+}
+
+func exportGrants(grants []models.DelegationGrant) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(grants))
+	for _, grant := range grants {
+		rows = append(rows, map[string]interface{}{
+			"id":           grant.ID,
+			"delegate_id":  grant.DelegateID,
+			"subject_id":   grant.SubjectID,
+			"subject_type": grant.SubjectType,
+			"scopes":       grant.ScopeList(),
+			"granted_by":   grant.GrantedBy,
+			"expires_at":   grant.ExpiresAt,
+			"revoked_at":   grant.RevokedAt,
+			"created_at":   grant.CreatedAt,
+		})
+	}
+	return rows
+}
+
+func exportCredentials(credentials []models.ProfessionalCredential) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(credentials))
+	for _, credential := range credentials {
+		rows = append(rows, map[string]interface{}{
+			"id":               credential.ID,
+			"license_number":   credential.LicenseNumber,
+			"issuing_province": credential.IssuingProvince,
+			"expires_on":       credential.ExpiresOn.Format("2006-01-02"),
+			"status":           credential.Status,
+			"reviewed_at":      credential.ReviewedAt,
+			"rejection_reason": credential.RejectionReason,
+			"created_at":       credential.CreatedAt,
+		})
+	}
+	return rows
+}
+
+func exportConsents(consents []models.Consent) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(consents))
+	for _, consent := range consents {
+		rows = append(rows, map[string]interface{}{
+			"policy":     consent.Policy,
+			"version":    consent.Version,
+			"granted":    consent.Granted,
+			"source":     consent.Source,
+			"created_at": consent.CreatedAt,
+		})
+	}
+	return rows
+}
+
+func exportDeletions(deletions []models.AccountDeletion) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(deletions))
+	for _, deletion := range deletions {
+		rows = append(rows, map[string]interface{}{
+			"id":            deletion.ID,
+			"scheduled_for": deletion.ScheduledFor,
+			"cancelled_at":  deletion.CancelledAt,
+			"completed_at":  deletion.CompletedAt,
+			"created_at":    deletion.CreatedAt,
+		})
+	}
+	return rows
+}
+
+func exportAuditEvents(events []models.AuditEvent) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		details := json.RawMessage(event.Details)
+		if len(details) == 0 {
+			details = json.RawMessage("{}")
+		}
+		rows = append(rows, map[string]interface{}{
+			"id":         event.ID,
+			"action":     event.Action,
+			"actor_id":   event.ActorID,
+			"subject_id": event.SubjectID,
+			"details":    details,
+			"created_at": event.CreatedAt,
+		})
+	}
+	return rows
+}
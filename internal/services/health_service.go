@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"fmt"
+
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type HealthService interface {
+	CheckReadiness(ctx context.Context) error
+}
+
+type healthService struct {
+	db             *sql.DB
+	signingKeyRepo repositories.SigningKeyRepository
+	jwtSecret      string
+}
+
+func NewHealthService(db *sql.DB, signingKeyRepo repositories.SigningKeyRepository, jwtSecret string) HealthService {
+	return &healthService{
+		db:             db,
+		signingKeyRepo: signingKeyRepo,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// CheckReadiness returns why the service cannot handle calls, or nil when it can: the database must answer
+// and tokens must be signable, with the active signing key or the configured secret when no key has been rotated in
+func (s *healthService) CheckReadiness(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unreachable: %w", err)
+	}
+
+	_, err := s.signingKeyRepo.WithContext(ctx).GetActiveKey()
+	if goerrors.Is(err, gorm.ErrRecordNotFound) {
+		if s.jwtSecret == "" {
+			return goerrors.New("no signing key and no JWT secret configured")
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("signing key unavailable: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, inviterID, email, role, storeID string) (*models.Invitation, string, error)
+	ListInvitations(ctx context.Context, status string, pageSize int, pageToken string) ([]models.Invitation, string, error)
+	ResendInvitation(ctx context.Context, invitationID string) (*models.Invitation, string, error)
+	RevokeInvitation(ctx context.Context, invitationID string) error
+	AcceptInvitation(ctx context.Context, code, username, password string) (string, error)
+}
+
+type invitationService struct {
+	invitationRepo repositories.InvitationRepository
+	userRepo       repositories.UserRepository
+	roleRepo       repositories.RoleRepository
+	audit          AuditService
+	ttl            time.Duration
+}
+
+func NewInvitationService(invitationRepo repositories.InvitationRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, auditService AuditService, ttl time.Duration) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		audit:          auditService,
+		ttl:            ttl,
+	}
+}
+
+// CreateInvitation issues an invitation for a staff member and returns it with its code.
+// The code is only returned here and by ResendInvitation, it is never stored.
+func (s *invitationService) CreateInvitation(ctx context.Context, inviterID, email, role, storeID string) (*models.Invitation, string, error) {
+	inviter, err := uuid.Parse(inviterID)
+	if err != nil {
+		return nil, "", errors.NewAuthError("invitation.invalid_inviter")
+	}
+
+	// Validate the invitation
+	if err := utils.ValidateInvitationInput(email, role); err != nil {
+		return nil, "", err
+	}
+
+	// Check that the role exists
+	if _, err := s.roleRepo.WithContext(ctx).GetRoleByName(role); err != nil {
+		return nil, "", errors.NewFieldError("role", i18n.M("role.not_exist", "role", role))
+	}
+
+	// Check that the email is not taken or already invited
+	if _, err := s.userRepo.WithContext(ctx).GetUserByEmail(email); err == nil {
+		return nil, "", errors.NewConflictError("user.email_taken").WithParams("email", email)
+	}
+	if _, err := s.invitationRepo.WithContext(ctx).GetPendingInvitationByEmail(email); err == nil {
+		return nil, "", errors.NewConflictError("invitation.pending_exists").WithParams("email", email)
+	}
+
+	code, codeHash, err := newInvitationCode()
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	now := time.Now()
+	invitation := &models.Invitation{
+		Email:     email,
+		Role:      role,
+		CodeHash:  codeHash,
+		InvitedBy: inviter,
+		ExpiresAt: now.Add(s.ttl),
+		SentAt:    now,
+	}
+	if storeID != "" {
+		invitation.StoreID = &storeID
+	}
+
+	if err := s.invitationRepo.WithContext(ctx).CreateInvitation(invitation); err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	utils.Info("Invitation created", map[string]interface{}{
+		"invitationID": invitation.ID.String(),
+		"role":         role,
+		"invitedBy":    inviterID,
+	})
+
+	return invitation, code, nil
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context, status string, pageSize int, pageToken string) ([]models.Invitation, string, error) {
+	switch status {
+	case "", "pending", "accepted", "revoked", "expired":
+	default:
+		return nil, "", errors.NewValidationError("status", "invitation.status_invalid")
+	}
+
+	switch {
+	case pageSize < 0:
+		return nil, "", errors.NewValidationError("page_size", "page.size_negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	offset := 0
+	if pageToken != "" {
+		var err error
+		offset, err = strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return nil, "", errors.NewValidationError("page_token", "page.invalid_token")
+		}
+	}
+
+	invitations, err := s.invitationRepo.WithContext(ctx).ListInvitations(status, pageSize+1, offset)
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	nextPageToken := ""
+	if len(invitations) > pageSize {
+		invitations = invitations[:pageSize]
+		nextPageToken = strconv.Itoa(offset + pageSize)
+	}
+
+	return invitations, nextPageToken, nil
+}
+
+// ResendInvitation replaces the code of a pending or expired invitation and restarts its expiry.
+// The previous code stops working.
+func (s *invitationService) ResendInvitation(ctx context.Context, invitationID string) (*models.Invitation, string, error) {
+	invitation, err := s.getInvitation(ctx, invitationID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch invitation.Status(time.Now()) {
+	case "accepted":
+		return nil, "", errors.NewConflictError("invitation.already_accepted")
+	case "revoked":
+		return nil, "", errors.NewConflictError("invitation.revoked")
+	}
+
+	code, codeHash, err := newInvitationCode()
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	now := time.Now()
+	invitation.CodeHash = codeHash
+	invitation.ExpiresAt = now.Add(s.ttl)
+	invitation.SentAt = now
+	if err := s.invitationRepo.WithContext(ctx).UpdateInvitation(invitation); err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	utils.Info("Invitation resent", map[string]interface{}{
+		"invitationID": invitationID,
+	})
+
+	return invitation, code, nil
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, invitationID string) error {
+	invitation, err := s.getInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+
+	switch invitation.Status(time.Now()) {
+	case "accepted":
+		return errors.NewConflictError("invitation.already_accepted")
+	case "revoked":
+		return errors.NewConflictError("invitation.already_revoked")
+	}
+
+	now := time.Now()
+	invitation.RevokedAt = &now
+	if err := s.invitationRepo.WithContext(ctx).UpdateInvitation(invitation); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Invitation revoked", map[string]interface{}{
+		"invitationID": invitationID,
+	})
+
+	return nil
+}
+
+// AcceptInvitation creates the invited staff account. Staff accounts have no customer profile,
+// so only a username and password are needed; the email, role and store come from the invitation.
+func (s *invitationService) AcceptInvitation(ctx context.Context, code, username, password string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		return "", errors.NewValidationError("code", "invitation.code_required")
+	}
+
+	invitation, err := s.invitationRepo.WithContext(ctx).GetInvitationByCodeHash(utils.HashToken(code))
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.NewNotFoundError("invitation.not_found")
+		}
+		return "", errors.NewInternalError(err)
+	}
+
+	switch invitation.Status(time.Now()) {
+	case "accepted":
+		return "", errors.NewConflictError("invitation.already_accepted")
+	case "revoked":
+		return "", errors.NewAuthError("invitation.revoked")
+	case "expired":
+		return "", errors.NewAuthError("invitation.expired")
+	}
+
+	// Validate before claiming so that a typo does not use up the invitation
+	if err := utils.ValidateAccountInput(username, invitation.Email, password, invitation.Role); err != nil {
+		return "", err
+	}
+
+	userID, err := s.createStaffUser(ctx, invitation, username, password)
+	if err != nil {
+		return "", err
+	}
+
+	utils.Info("Invitation accepted", map[string]interface{}{
+		"invitationID": invitation.ID.String(),
+		"userID":       userID.String(),
+		"role":         invitation.Role,
+	})
+	s.audit.Record(ctx, "user.created", invitation.InvitedBy.String(), userID.String(), map[string]interface{}{
+		"role":         invitation.Role,
+		"invitationID": invitation.ID.String(),
+	})
+
+	return userID.String(), nil
+}
+
+// createStaffUser creates the staff account of an invitation with its role and store, claiming the invitation
+// in the same transaction so that a failure leaves neither the email taken nor the invitation used up
+func (s *invitationService) createStaffUser(ctx context.Context, invitation *models.Invitation, username, password string) (uuid.UUID, error) {
+	if _, err := s.userRepo.WithContext(ctx).GetUserByEmail(invitation.Email); err == nil {
+		return uuid.Nil, errors.NewConflictError("user.email_taken").WithParams("email", invitation.Email)
+	}
+	if _, err := s.userRepo.WithContext(ctx).GetUserByUserName(username); err == nil {
+		return uuid.Nil, errors.NewConflictError("user.username_taken").WithParams("username", username)
+	}
+
+	role, err := s.roleRepo.WithContext(ctx).GetRoleByName(invitation.Role)
+	if err != nil {
+		return uuid.Nil, errors.NewFieldError("role", i18n.M("role.not_exist", "role", invitation.Role))
+	}
+
+	passwordHash, err := utils.HashPassword(ctx, password)
+	if err != nil {
+		return uuid.Nil, errors.NewInternalError(err)
+	}
+
+	user := &models.User{
+		Username:     username,
+		Email:        invitation.Email,
+		PasswordHash: passwordHash,
+		Role:         invitation.Role,
+		StoreID:      invitation.StoreID,
+	}
+
+	// The user ID is set on the event when the user is created
+	created := models.NewOutboxEvent(models.EventUserRegistered, uuid.Nil, map[string]interface{}{
+		"username": username,
+		"email":    invitation.Email,
+		"role":     invitation.Role,
+		"source":   "invitation",
+	})
+	accepted, err := s.invitationRepo.WithContext(ctx).AcceptInvitation(invitation.ID.String(), user, role.ID, created)
+	if err != nil {
+		return uuid.Nil, errors.NewInternalError(err)
+	}
+	if !accepted {
+		return uuid.Nil, errors.NewConflictError("invitation.already_accepted")
+	}
+
+	return user.ID, nil
+}
+
+func (s *invitationService) getInvitation(ctx context.Context, invitationID string) (*models.Invitation, error) {
+	if _, err := uuid.Parse(invitationID); err != nil {
+		return nil, errors.NewValidationError("invitation_id", "invitation.invalid_id")
+	}
+
+	invitation, err := s.invitationRepo.WithContext(ctx).GetInvitationByID(invitationID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("invitation.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return invitation, nil
+}
+
+// newInvitationCode returns a random invite code and the hash that is stored in its place
+func newInvitationCode() (string, string, error) {
+	code, err := utils.GenerateRandomHex(16)
+	if err != nil {
+		return "", "", err
+	}
+	return code, utils.HashToken(code), nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/outbox"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+)
+
+const (
+	// outboxBatchSize is the number of events claimed at a time
+	outboxBatchSize = 100
+	// outboxLease is how long a claimed event is hidden from other relays
+	outboxLease = time.Minute
+	// retryMaxBackoff caps the delay between attempts
+	retryMaxBackoff = time.Hour
+)
+
+// OutboxService relays the domain events written to the outbox to the configured publisher
+type OutboxService interface {
+	RelayEvents(ctx context.Context) error
+}
+
+type outboxService struct {
+	outboxRepo  repositories.OutboxRepository
+	publisher   outbox.Publisher
+	maxAttempts int
+	retention   time.Duration
+}
+
+func NewOutboxService(outboxRepo repositories.OutboxRepository, publisher outbox.Publisher, maxAttempts int, retention time.Duration) OutboxService {
+	return &outboxService{
+		outboxRepo:  outboxRepo,
+		publisher:   publisher,
+		maxAttempts: maxAttempts,
+		retention:   retention,
+	}
+}
+
+// RelayEvents publishes the events that are due until none are left, then deletes the events
+// published longer ago than the retention period. Failed events are retried with exponential backoff
+// until maxAttempts is reached.
+func (s *outboxService) RelayEvents(ctx context.Context) error {
+	for {
+		events, err := s.outboxRepo.WithContext(ctx).ClaimDueEvents(outboxBatchSize, outboxLease)
+		if err != nil {
+			return err
+		}
+
+		for i := range events {
+			s.publish(ctx, &events[i])
+		}
+
+		// Publishing an event makes the next event of the same user due
+		if len(events) == 0 {
+			break
+		}
+	}
+
+	deleted, err := s.outboxRepo.WithContext(ctx).DeletePublishedEvents(time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		utils.Info("Published events deleted", map[string]interface{}{
+			"count": deleted,
+		})
+	}
+	return nil
+}
+
+func (s *outboxService) publish(ctx context.Context, event *models.OutboxEvent) {
+	err := s.publisher.Publish(ctx, outbox.NewMessage(event))
+	now := time.Now()
+	event.Attempts++
+
+	if err == nil {
+		event.PublishedAt = &now
+		event.LastError = nil
+	} else {
+		message := err.Error()
+		event.LastError = &message
+		if event.Attempts >= s.maxAttempts {
+			event.FailedAt = &now
+			utils.Error("Giving up on publishing event", map[string]interface{}{
+				"eventID":  event.ID.String(),
+				"type":     event.Type,
+				"attempts": event.Attempts,
+				"error":    message,
+			})
+		} else {
+			event.NextAttemptAt = now.Add(retryBackoff(event.Attempts))
+			utils.Warn("Failed to publish event, will retry", map[string]interface{}{
+				"eventID":     event.ID.String(),
+				"type":        event.Type,
+				"attempts":    event.Attempts,
+				"nextAttempt": event.NextAttemptAt.Format(time.RFC3339),
+				"error":       message,
+			})
+		}
+	}
+
+	// If this fails after a successful publish the event is sent again, consumers drop it by ID
+	if err := s.outboxRepo.WithContext(ctx).UpdateEvent(event); err != nil {
+		utils.Error("Failed to update outbox event", map[string]interface{}{
+			"eventID": event.ID.String(),
+			"error":   err.Error(),
+		})
+	}
+}
+
+// retryBackoff returns the delay before the next attempt: 2s, 4s, 8s and so on, up to retryMaxBackoff
+func retryBackoff(attempts int) time.Duration {
+	if attempts > 12 {
+		return retryMaxBackoff
+	}
+	delay := time.Second << attempts
+	if delay > retryMaxBackoff {
+		return retryMaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"sort"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Profile is a customer's profile together with the login details of their account
+type Profile struct {
+	models.Customer
+	Username string
+	Email    string
+}
+
+type ProfileService interface {
+	GetProfile(ctx context.Context, userID string) (*Profile, error)
+	UpdateProfile(ctx context.Context, userID string, fields map[string]string, version int) (*Profile, error)
+}
+
+type profileService struct {
+	userRepo     repositories.UserRepository
+	customerRepo repositories.CustomerRepository
+}
+
+func NewProfileService(userRepo repositories.UserRepository, customerRepo repositories.CustomerRepository) ProfileService {
+	return &profileService{
+		userRepo:     userRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+func (s *profileService) GetProfile(ctx context.Context, userID string) (*Profile, error) {
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("user.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	customer, err := s.customerRepo.WithContext(ctx).GetCustomerByUserID(userID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("profile.not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	return &Profile{Customer: *customer, Username: user.Username, Email: user.Email}, nil
+}
+
+// UpdateProfile changes the given fields, keyed by field name, of the user's profile.
+// The update is rejected when the profile is no longer at version, so concurrent edits cannot overwrite each other.
+func (s *profileService) UpdateProfile(ctx context.Context, userID string, fields map[string]string, version int) (*Profile, error) {
+	if err := utils.ValidateProfileUpdate(fields); err != nil {
+		return nil, err
+	}
+	if version <= 0 {
+		return nil, errors.NewValidationError("version", "profile.version_required")
+	}
+
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Version != version {
+		return nil, errors.NewConflictError("profile.version_conflict")
+	}
+
+	// Field names match the column names
+	updates := make(map[string]interface{}, len(fields))
+	for field, value := range fields {
+		updates[field] = strings.TrimSpace(value)
+	}
+	if phone, ok := fields["phone"]; ok {
+		updates["phone"], _ = utils.NormalizePhone(phone, "")
+	}
+
+	event := models.NewOutboxEvent(models.EventUserUpdated, profile.UserID, map[string]interface{}{
+		"changes": updates,
+	})
+	updated, err := s.customerRepo.WithContext(ctx).UpdateCustomerFields(profile.ID, updates, version, event)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if !updated {
+		return nil, errors.NewConflictError("profile.version_conflict")
+	}
+
+	utils.Info("Profile updated", map[string]interface{}{
+		"userID": userID,
+		"fields": strings.Join(sortedKeys(fields), ","),
+	})
+
+	return s.GetProfile(ctx, userID)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
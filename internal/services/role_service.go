@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	goerrors "errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	CreateRole(ctx context.Context, actorID, name, description string, permissions []string) (*models.Role, error)
+	UpdateRole(ctx context.Context, actorID, name string, description *string, permissions []string, replacePermissions bool) (*models.Role, error)
+	DeleteRole(ctx context.Context, actorID, name string) error
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	AssignRole(ctx context.Context, actorID, userID, roleName string) error
+	RevokeRole(ctx context.Context, actorID, userID, roleName string) error
+}
+
+type roleService struct {
+	userRepo repositories.UserRepository
+	roleRepo repositories.RoleRepository
+	audit    AuditService
+}
+
+func NewRoleService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, auditService AuditService) RoleService {
+	return &roleService{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+		audit:    auditService,
+	}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	roles, err := s.roleRepo.WithContext(ctx).ListRoles()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return roles, nil
+}
+
+func (s *roleService) CreateRole(ctx context.Context, actorID, name, description string, permissions []string) (*models.Role, error) {
+	if err := utils.ValidateRoleName(name); err != nil {
+		return nil, err
+	}
+
+	// Check if the role already exists
+	if _, err := s.roleRepo.WithContext(ctx).GetRoleByName(name); err == nil {
+		return nil, errors.NewConflictError("role.exists").WithParams("role", name)
+	}
+
+	rolePermissions, err := s.lookupPermissions(ctx, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        name,
+		Description: description,
+		Permissions: rolePermissions,
+	}
+	if err := s.roleRepo.WithContext(ctx).CreateRole(role); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Role created", map[string]interface{}{
+		"role":        name,
+		"permissions": permissions,
+	})
+	s.audit.Record(ctx, "role.created", actorID, "", map[string]interface{}{
+		"role":        name,
+		"permissions": permissions,
+	})
+
+	return role, nil
+}
+
+func (s *roleService) UpdateRole(ctx context.Context, actorID, name string, description *string, permissions []string, replacePermissions bool) (*models.Role, error) {
+	role, err := s.getRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if description != nil {
+		role.Description = *description
+	}
+
+	var rolePermissions []models.Permission
+	if replacePermissions {
+		if name == "admin" {
+			return nil, errors.NewBadRequestError("role.admin_permissions")
+		}
+		rolePermissions, err = s.lookupPermissions(ctx, permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.roleRepo.WithContext(ctx).UpdateRole(role, rolePermissions); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Role updated", map[string]interface{}{
+		"role":        name,
+		"permissions": permissions,
+	})
+	details := map[string]interface{}{"role": name}
+	if replacePermissions {
+		details["permissions"] = permissions
+	}
+	s.audit.Record(ctx, "role.updated", actorID, "", details)
+
+	return s.getRole(ctx, name)
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, actorID, name string) error {
+	role, err := s.getRole(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if role.System {
+		return errors.NewBadRequestError("role.built_in").WithParams("role", name)
+	}
+
+	count, err := s.roleRepo.WithContext(ctx).CountUsersWithPrimaryRole(name)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+	if count > 0 {
+		return errors.NewConflictError("role.in_use").WithParams("role", name, "count", strconv.FormatInt(count, 10))
+	}
+
+	if err := s.roleRepo.WithContext(ctx).DeleteRole(role); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Role deleted", map[string]interface{}{
+		"role": name,
+	})
+	s.audit.Record(ctx, "role.deleted", actorID, "", map[string]interface{}{
+		"role": name,
+	})
+
+	return nil
+}
+
+func (s *roleService) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	permissions, err := s.roleRepo.WithContext(ctx).ListPermissions()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return permissions, nil
+}
+
+func (s *roleService) AssignRole(ctx context.Context, actorID, userID, roleName string) error {
+	user, role, err := s.getUserAndRole(ctx, userID, roleName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.WithContext(ctx).AssignRole(user.ID, role.ID); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Role assigned", map[string]interface{}{
+		"userID": userID,
+		"role":   roleName,
+	})
+	s.audit.Record(ctx, "role.assigned", actorID, userID, map[string]interface{}{
+		"role": roleName,
+	})
+
+	return nil
+}
+
+func (s *roleService) RevokeRole(ctx context.Context, actorID, userID, roleName string) error {
+	user, role, err := s.getUserAndRole(ctx, userID, roleName)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == roleName {
+		return errors.NewBadRequestError("role.primary_revoke")
+	}
+
+	if err := s.roleRepo.WithContext(ctx).RevokeRole(user.ID, role.ID); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Role revoked", map[string]interface{}{
+		"userID": userID,
+		"role":   roleName,
+	})
+	s.audit.Record(ctx, "role.revoked", actorID, userID, map[string]interface{}{
+		"role": roleName,
+	})
+
+	return nil
+}
+
+func (s *roleService) getRole(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.roleRepo.WithContext(ctx).GetRoleByName(name)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("role.not_found").WithParams("role", name)
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return role, nil
+}
+
+func (s *roleService) getUserAndRole(ctx context.Context, userID, roleName string) (*models.User, *models.Role, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, nil, errors.NewValidationError("user_id", "user.invalid_id")
+	}
+
+	user, err := s.userRepo.WithContext(ctx).GetUserByID(userID)
+	if err != nil {
+		return nil, nil, errors.NewNotFoundError("user.not_found")
+	}
+
+	role, err := s.getRole(ctx, roleName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, role, nil
+}
+
+// lookupPermissions loads the named permissions, failing on unknown names
+func (s *roleService) lookupPermissions(ctx context.Context, names []string) ([]models.Permission, error) {
+	if len(names) == 0 {
+		return []models.Permission{}, nil
+	}
+
+	permissions, err := s.roleRepo.WithContext(ctx).GetPermissionsByName(names)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+
+	var unknown []string
+	for _, name := range names {
+		if !found[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.NewFieldError("permissions", i18n.M("role.unknown_permissions", "permissions", strings.Join(unknown, ", ")))
+	}
+
+	return permissions, nil
+}
@@ -0,0 +1,469 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PharmaKart/authentication-svc/internal/models"
+	"github.com/PharmaKart/authentication-svc/internal/outbox"
+	"github.com/PharmaKart/authentication-svc/internal/repositories"
+	"github.com/PharmaKart/authentication-svc/internal/webhook"
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"github.com/PharmaKart/authentication-svc/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookBatchSize is the number of deliveries claimed at a time
+	webhookBatchSize = 50
+	// webhookLease is how long a claimed delivery is hidden from other workers
+	webhookLease = 2 * time.Minute
+)
+
+// WebhookService manages partner webhook subscriptions and delivers the domain events they select.
+// It is an outbox publisher: each published event is queued for every matching subscription.
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, actorID, endpoint, description string, eventTypes []string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscriptionID string, endpoint, description *string, eventTypes []string, allEvents bool, active *bool) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	ListDeadLetters(ctx context.Context, subscriptionID string, pageSize int, pageToken string) ([]models.WebhookDeadLetter, string, error)
+	ReplayDeadLetter(ctx context.Context, deadLetterID string) (*models.WebhookDelivery, error)
+	Publish(ctx context.Context, message outbox.Message) error
+	DeliverDue(ctx context.Context) error
+}
+
+type webhookService struct {
+	webhookRepo repositories.WebhookRepository
+	client      *webhook.Client
+	maxAttempts int
+	retention   time.Duration
+}
+
+func NewWebhookService(webhookRepo repositories.WebhookRepository, client *webhook.Client, maxAttempts int, retention time.Duration) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		client:      client,
+		maxAttempts: maxAttempts,
+		retention:   retention,
+	}
+}
+
+// CreateSubscription adds a subscription for the given event types, or all events when none are given.
+// The returned subscription holds the signing secret the partner needs to verify deliveries.
+func (s *webhookService) CreateSubscription(ctx context.Context, actorID, endpoint, description string, eventTypes []string) (*models.WebhookSubscription, error) {
+	createdBy, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, errors.NewAuthError("auth.invalid_user")
+	}
+
+	validationErrors := make(map[string]i18n.Message)
+	endpoint = strings.TrimSpace(endpoint)
+	if message := validateWebhookURL(endpoint); message.Key != "" {
+		validationErrors["url"] = message
+	}
+	description = strings.TrimSpace(description)
+	if len(description) > 200 {
+		validationErrors["description"] = i18n.M("webhook.description_too_long")
+	}
+	types, message := normalizeEventTypes(eventTypes)
+	if message.Key != "" {
+		validationErrors["event_types"] = message
+	}
+	if len(validationErrors) > 0 {
+		return nil, errors.NewFieldErrors(validationErrors)
+	}
+
+	secret, err := utils.GenerateRandomHex(32)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:         endpoint,
+		Description: description,
+		EventTypes:  strings.Join(types, " "),
+		Secret:      secret,
+		Active:      true,
+		CreatedBy:   createdBy,
+	}
+	if err := s.webhookRepo.WithContext(ctx).CreateSubscription(subscription); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Webhook subscription created", map[string]interface{}{
+		"subscriptionID": subscription.ID.String(),
+		"eventTypes":     subscription.EventTypes,
+		"createdBy":      actorID,
+	})
+
+	return subscription, nil
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.webhookRepo.WithContext(ctx).ListSubscriptions()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription changes the given fields. Event types are replaced when some are given,
+// allEvents clears the filter. Pausing a subscription stops new deliveries, queued ones are still sent.
+func (s *webhookService) UpdateSubscription(ctx context.Context, subscriptionID string, endpoint, description *string, eventTypes []string, allEvents bool, active *bool) (*models.WebhookSubscription, error) {
+	subscription, err := s.getSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	validationErrors := make(map[string]i18n.Message)
+	if endpoint != nil {
+		subscription.URL = strings.TrimSpace(*endpoint)
+		if message := validateWebhookURL(subscription.URL); message.Key != "" {
+			validationErrors["url"] = message
+		}
+	}
+	if description != nil {
+		subscription.Description = strings.TrimSpace(*description)
+		if len(subscription.Description) > 200 {
+			validationErrors["description"] = i18n.M("webhook.description_too_long")
+		}
+	}
+	switch {
+	case allEvents && len(eventTypes) > 0:
+		validationErrors["event_types"] = i18n.M("webhook.event_types_with_all")
+	case allEvents:
+		subscription.EventTypes = ""
+	case len(eventTypes) > 0:
+		types, message := normalizeEventTypes(eventTypes)
+		if message.Key != "" {
+			validationErrors["event_types"] = message
+		}
+		subscription.EventTypes = strings.Join(types, " ")
+	}
+	if active != nil {
+		subscription.Active = *active
+	}
+	if len(validationErrors) > 0 {
+		return nil, errors.NewFieldErrors(validationErrors)
+	}
+
+	subscription.UpdatedAt = time.Now()
+	if err := s.webhookRepo.WithContext(ctx).UpdateSubscription(subscription); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Webhook subscription updated", map[string]interface{}{
+		"subscriptionID": subscriptionID,
+		"eventTypes":     subscription.EventTypes,
+		"active":         subscription.Active,
+	})
+
+	return subscription, nil
+}
+
+// DeleteSubscription removes the subscription, its queued deliveries and its dead letters
+func (s *webhookService) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	if _, err := s.getSubscription(ctx, subscriptionID); err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.WithContext(ctx).DeleteSubscription(subscriptionID); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	utils.Info("Webhook subscription deleted", map[string]interface{}{
+		"subscriptionID": subscriptionID,
+	})
+
+	return nil
+}
+
+// ListDeadLetters returns a page of deliveries that failed every attempt, newest first
+func (s *webhookService) ListDeadLetters(ctx context.Context, subscriptionID string, pageSize int, pageToken string) ([]models.WebhookDeadLetter, string, error) {
+	if subscriptionID != "" {
+		if _, err := uuid.Parse(subscriptionID); err != nil {
+			return nil, "", errors.NewValidationError("subscription_id", "webhook.invalid_subscription_id")
+		}
+	}
+
+	switch {
+	case pageSize < 0:
+		return nil, "", errors.NewValidationError("page_size", "page.size_negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	offset := 0
+	if pageToken != "" {
+		var err error
+		offset, err = strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return nil, "", errors.NewValidationError("page_token", "page.invalid_token")
+		}
+	}
+
+	deadLetters, err := s.webhookRepo.WithContext(ctx).ListDeadLetters(subscriptionID, pageSize+1, offset)
+	if err != nil {
+		return nil, "", errors.NewInternalError(err)
+	}
+
+	nextPageToken := ""
+	if len(deadLetters) > pageSize {
+		deadLetters = deadLetters[:pageSize]
+		nextPageToken = strconv.Itoa(offset + pageSize)
+	}
+
+	return deadLetters, nextPageToken, nil
+}
+
+// ReplayDeadLetter queues the dead letter's event for its subscription again, with a fresh set of attempts
+func (s *webhookService) ReplayDeadLetter(ctx context.Context, deadLetterID string) (*models.WebhookDelivery, error) {
+	if _, err := uuid.Parse(deadLetterID); err != nil {
+		return nil, errors.NewValidationError("dead_letter_id", "webhook.invalid_dead_letter_id")
+	}
+
+	deadLetter, err := s.webhookRepo.WithContext(ctx).GetDeadLetter(deadLetterID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("webhook.dead_letter_not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	if deadLetter.ReplayedAt != nil {
+		return nil, errors.NewConflictError("webhook.dead_letter_replayed")
+	}
+
+	now := time.Now()
+	deadLetter.ReplayedAt = &now
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: deadLetter.SubscriptionID,
+		EventID:        deadLetter.EventID,
+		EventType:      deadLetter.EventType,
+		UserID:         deadLetter.UserID,
+		Payload:        deadLetter.Payload,
+		NextAttemptAt:  now,
+	}
+	if err := s.webhookRepo.WithContext(ctx).ReplayDeadLetter(deadLetter, delivery); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	utils.Info("Webhook dead letter replayed", map[string]interface{}{
+		"deadLetterID":   deadLetterID,
+		"deliveryID":     delivery.ID.String(),
+		"subscriptionID": delivery.SubscriptionID.String(),
+	})
+
+	return delivery, nil
+}
+
+// Publish queues the event for every active subscription that selects it.
+// An event published twice is only queued once per subscription.
+func (s *webhookService) Publish(ctx context.Context, message outbox.Message) error {
+	subscriptions, err := s.webhookRepo.WithContext(ctx).GetActiveSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	eventID, err := uuid.Parse(message.ID)
+	if err != nil {
+		return err
+	}
+	userID, err := uuid.Parse(message.UserID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(message.Type) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			EventType:      message.Type,
+			UserID:         userID,
+			Payload:        string(payload),
+			NextAttemptAt:  time.Now(),
+		})
+	}
+	return s.webhookRepo.WithContext(ctx).CreateDeliveries(deliveries)
+}
+
+// DeliverDue sends the deliveries that are due until none are left, then deletes the deliveries
+// that succeeded longer ago than the retention period. Failed deliveries are retried with exponential
+// backoff and moved to the dead-letter table after maxAttempts.
+func (s *webhookService) DeliverDue(ctx context.Context) error {
+	subscriptions := make(map[uuid.UUID]*models.WebhookSubscription)
+
+	for {
+		deliveries, err := s.webhookRepo.WithContext(ctx).ClaimDueDeliveries(webhookBatchSize, webhookLease)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			break
+		}
+
+		for i := range deliveries {
+			delivery := &deliveries[i]
+			subscription, ok := subscriptions[delivery.SubscriptionID]
+			if !ok {
+				subscription, err = s.webhookRepo.WithContext(ctx).GetSubscription(delivery.SubscriptionID.String())
+				// The subscription was deleted with its deliveries after they were claimed
+				if goerrors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				subscriptions[delivery.SubscriptionID] = subscription
+			}
+			s.deliver(ctx, subscription, delivery)
+		}
+	}
+
+	deleted, err := s.webhookRepo.WithContext(ctx).DeleteDeliveredDeliveries(time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		utils.Info("Delivered webhooks deleted", map[string]interface{}{
+			"count": deleted,
+		})
+	}
+	return nil
+}
+
+func (s *webhookService) deliver(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	status, err := s.client.Send(ctx, webhook.Request{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		DeliveryID: delivery.ID.String(),
+		EventID:    delivery.EventID.String(),
+		EventType:  delivery.EventType,
+		Body:       []byte(delivery.Payload),
+	})
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatus = status
+
+	if err == nil {
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+		if err := s.webhookRepo.WithContext(ctx).UpdateDelivery(delivery); err != nil {
+			utils.Error("Failed to update webhook delivery", map[string]interface{}{
+				"deliveryID": delivery.ID.String(),
+				"error":      err.Error(),
+			})
+		}
+		return
+	}
+
+	message := err.Error()
+	delivery.LastError = &message
+	fields := map[string]interface{}{
+		"deliveryID":     delivery.ID.String(),
+		"subscriptionID": subscription.ID.String(),
+		"eventType":      delivery.EventType,
+		"attempts":       delivery.Attempts,
+		"status":         status,
+		"error":          message,
+	}
+
+	if delivery.Attempts >= s.maxAttempts {
+		deadLetter, err := s.webhookRepo.WithContext(ctx).DeadLetterDelivery(delivery)
+		if err != nil {
+			fields["error"] = err.Error()
+			utils.Error("Failed to dead-letter webhook delivery", fields)
+			return
+		}
+		fields["deadLetterID"] = deadLetter.ID.String()
+		utils.Error("Webhook delivery moved to dead letters", fields)
+		return
+	}
+
+	delivery.NextAttemptAt = now.Add(retryBackoff(delivery.Attempts))
+	fields["nextAttempt"] = delivery.NextAttemptAt.Format(time.RFC3339)
+	utils.Warn("Webhook delivery failed, will retry", fields)
+	if err := s.webhookRepo.WithContext(ctx).UpdateDelivery(delivery); err != nil {
+		utils.Error("Failed to update webhook delivery", map[string]interface{}{
+			"deliveryID": delivery.ID.String(),
+			"error":      err.Error(),
+		})
+	}
+}
+
+func (s *webhookService) getSubscription(ctx context.Context, subscriptionID string) (*models.WebhookSubscription, error) {
+	if _, err := uuid.Parse(subscriptionID); err != nil {
+		return nil, errors.NewValidationError("subscription_id", "webhook.invalid_subscription_id")
+	}
+
+	subscription, err := s.webhookRepo.WithContext(ctx).GetSubscription(subscriptionID)
+	if err != nil {
+		if goerrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("webhook.subscription_not_found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return subscription, nil
+}
+
+// validateWebhookURL requires an absolute HTTPS URL. Plain HTTP is only accepted for loopback hosts,
+// so that deliveries can be tried against a local stand-in. It returns a message without key when the URL is valid.
+func validateWebhookURL(endpoint string) i18n.Message {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return i18n.M("webhook.url_invalid")
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return i18n.Message{}
+	case "http":
+		host := parsed.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return i18n.Message{}
+		}
+		return i18n.M("webhook.url_http_loopback")
+	default:
+		return i18n.M("webhook.url_https")
+	}
+}
+
+// normalizeEventTypes removes duplicates and checks every type is known. It returns a message when one is not.
+func normalizeEventTypes(eventTypes []string) ([]string, i18n.Message) {
+	known := make(map[string]bool, len(models.EventTypes))
+	for _, t := range models.EventTypes {
+		known[t] = true
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	types := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		t = strings.TrimSpace(t)
+		if !known[t] {
+			return nil, i18n.M("webhook.unknown_event_type", "type", t, "types", strings.Join(models.EventTypes, ", "))
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	return types, i18n.Message{}
+}
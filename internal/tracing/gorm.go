@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey stores the span of a query in the statement's settings, between the before and after callbacks
+const spanKey = "tracing:span"
+
+// InstrumentDB starts a span around each query made through db, named after the operation and table,
+// e.g. "gorm.query users". Queries made with WithContext are children of the span in that context.
+func InstrumentDB(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", startQuery("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", endQuery),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", startQuery("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", endQuery),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", startQuery("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", endQuery),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", startQuery("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", endQuery),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", startQuery("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", endQuery),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", startQuery("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", endQuery),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startQuery returns a callback starting the span of a query
+func startQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := tracer.Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			))
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// endQuery ends the span of a query with its statement and the rows it affected
+func endQuery(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	// A missing record is an answer, not a failure of the query
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
@@ -0,0 +1,92 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter, W3C trace context propagation,
+// and spans around database queries
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies the service in traces
+const ServiceName = "authentication-svc"
+
+// tracer creates the spans of the service. It uses the global provider, a no-op until Setup installs one.
+var tracer = otel.Tracer("github.com/PharmaKart/authentication-svc")
+
+// Start starts a span, a child of the span in ctx if any
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records err, if any, on the span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Setup installs the exporter and the W3C trace context propagator, and returns a function flushing
+// the spans left on shutdown. The exporter is:
+//   - none: spans are not recorded
+//   - stdout: spans are written as JSON to target, a file path, or to standard output when target is empty
+//   - otlp: spans are sent over gRPC to the OTLP collector at target, host:port, or to the endpoint
+//     in the OTEL_EXPORTER_OTLP_ENDPOINT environment variable when target is empty
+func Setup(ctx context.Context, exporter, target string) (func(context.Context) error, error) {
+	// Accept the trace context of callers even when spans are not recorded, so that it is passed on
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		var w io.Writer = os.Stdout
+		if target != "" {
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, err
+			}
+			w = file
+		}
+		e, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		spanExporter = e
+	case "otlp":
+		options := []otlptracegrpc.Option{}
+		if target != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(target), otlptracegrpc.WithInsecure())
+		}
+		e, err := otlptracegrpc.New(ctx, options...)
+		if err != nil {
+			return nil, err
+		}
+		spanExporter = e
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected none, stdout or otlp", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		// Receivers compute HMAC-SHA256(secret, "<timestamp>.<body>"), e.g. with openssl dgst -sha256 -hmac
+		{name: "known vector", secret: "secret", timestamp: 1700000000, body: "{}", want: "v1=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Fatalf("Sign() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	base := Sign("secret", 1700000000, []byte("{}"))
+	for name, other := range map[string]string{
+		"secret":    Sign("other", 1700000000, []byte("{}")),
+		"timestamp": Sign("secret", 1700000001, []byte("{}")),
+		"body":      Sign("secret", 1700000000, []byte("[]")),
+	} {
+		if other == base {
+			t.Errorf("Sign() does not depend on the %s", name)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"user.registered"}`)
+	signature := Sign("secret", now.Unix(), body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		now       time.Time
+		wantErr   bool
+	}{
+		{name: "valid", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now},
+		{name: "within tolerance", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now.Add(DefaultTolerance)},
+		{name: "wrong secret", secret: "other", signature: signature, timestamp: timestamp, body: body, now: now, wantErr: true},
+		{name: "tampered body", secret: "secret", signature: signature, timestamp: timestamp, body: []byte(`{"type":"user.deleted"}`), now: now, wantErr: true},
+		{name: "replayed later", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now.Add(DefaultTolerance + time.Second), wantErr: true},
+		{name: "timestamp in the future", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now.Add(-DefaultTolerance - time.Second), wantErr: true},
+		{name: "timestamp changed", secret: "secret", signature: signature, timestamp: strconv.FormatInt(now.Unix()+1, 10), body: body, now: now, wantErr: true},
+		{name: "invalid timestamp", secret: "secret", signature: signature, timestamp: "yesterday", body: body, now: now, wantErr: true},
+		{name: "unknown version", secret: "secret", signature: "v2=" + signature[3:], timestamp: timestamp, body: body, now: now, wantErr: true},
+		{name: "missing signature", secret: "secret", signature: "", timestamp: timestamp, body: body, now: now, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.signature, tt.timestamp, tt.body, DefaultTolerance, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientSend(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus int
+		wantErr    bool
+	}{
+		{name: "accepted", status: http.StatusNoContent, wantStatus: http.StatusNoContent},
+		{name: "rejected", status: http.StatusUnauthorized, wantStatus: http.StatusUnauthorized, wantErr: true},
+		{name: "failed", status: http.StatusInternalServerError, wantStatus: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verifyErr error
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				header = r.Header
+				verifyErr = Verify("secret", r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), body, DefaultTolerance, time.Now())
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			status, err := NewClient(time.Second).Send(context.Background(), Request{
+				URL:        server.URL,
+				Secret:     "secret",
+				DeliveryID: "delivery-1",
+				EventID:    "event-1",
+				EventType:  "user.registered",
+				Body:       []byte(`{"type":"user.registered"}`),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus {
+				t.Fatalf("Send() status = %d, want %d", status, tt.wantStatus)
+			}
+			if verifyErr != nil {
+				t.Fatalf("receiver could not verify the delivery: %v", verifyErr)
+			}
+			for name, want := range map[string]string{HeaderEventID: "event-1", HeaderEventType: "user.registered", HeaderDeliveryID: "delivery-1"} {
+				if got := header.Get(name); got != want {
+					t.Errorf("header %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientSendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	status, err := NewClient(time.Second).Send(context.Background(), Request{URL: url, Secret: "secret", Body: []byte("{}")})
+	if err == nil || status != 0 {
+		t.Fatalf("Send() = %d, %v, want 0 and an error", status, err)
+	}
+}
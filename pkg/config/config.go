@@ -4,14 +4,42 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port         string
-	JWTSecret    string
-	DBConnString string
+	Port                       string
+	MetricsPort                string
+	HealthCheckInterval        time.Duration
+	ShutdownTimeout            time.Duration
+	JWTSecret                  string
+	DBConnString               string
+	PolicyFile                 string
+	PolicyReloadInterval       time.Duration
+	InvitationTTL              time.Duration
+	CredentialCheckInterval    time.Duration
+	CredentialExpiryWarning    time.Duration
+	EligibilityFile            string
+	MinRegistrationAge         int
+	AccountDeletionGracePeriod time.Duration
+	ErasureCheckInterval       time.Duration
+	DataExportTTL              time.Duration
+	DataExportCheckInterval    time.Duration
+	EventSink                  string
+	EventSinkTarget            string
+	TraceExporter              string
+	TraceExporterTarget        string
+	OutboxRelayInterval        time.Duration
+	OutboxMaxAttempts          int
+	OutboxRetention            time.Duration
+	WebhookDeliveryInterval    time.Duration
+	WebhookMaxAttempts         int
+	WebhookTimeout             time.Duration
+	LegacyErrorResponses       bool
+	DevMode                    bool
 }
 
 func LoadConfig() *Config {
@@ -21,9 +49,35 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		Port:         getEnv("PORT", "50051"),
-		JWTSecret:    getEnv("JWT_SECRET", "your-secret-key"),
-		DBConnString: getDBConnString(),
+		Port:                       getEnv("PORT", "50051"),
+		MetricsPort:                getEnv("METRICS_PORT", "9090"),
+		HealthCheckInterval:        getEnvDuration("HEALTH_CHECK_INTERVAL", 5*time.Second),
+		ShutdownTimeout:            getEnvDuration("SHUTDOWN_TIMEOUT", 25*time.Second),
+		JWTSecret:                  getEnv("JWT_SECRET", "your-secret-key"),
+		DBConnString:               getDBConnString(),
+		PolicyFile:                 getEnv("POLICY_FILE", "policies.json"),
+		PolicyReloadInterval:       getEnvDuration("POLICY_RELOAD_INTERVAL", 10*time.Second),
+		InvitationTTL:              getEnvDuration("INVITATION_TTL", 72*time.Hour),
+		CredentialCheckInterval:    getEnvDuration("CREDENTIAL_CHECK_INTERVAL", time.Hour),
+		CredentialExpiryWarning:    getEnvDuration("CREDENTIAL_EXPIRY_WARNING", 30*24*time.Hour),
+		EligibilityFile:            getEnv("ELIGIBILITY_FILE", "eligibility.json"),
+		MinRegistrationAge:         getEnvInt("MIN_REGISTRATION_AGE", 16),
+		AccountDeletionGracePeriod: getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+		ErasureCheckInterval:       getEnvDuration("ERASURE_CHECK_INTERVAL", time.Hour),
+		DataExportTTL:              getEnvDuration("DATA_EXPORT_TTL", 24*time.Hour),
+		DataExportCheckInterval:    getEnvDuration("DATA_EXPORT_CHECK_INTERVAL", time.Minute),
+		EventSink:                  getEnv("EVENT_SINK", "stdout"),
+		EventSinkTarget:            getEnv("EVENT_SINK_TARGET", ""),
+		TraceExporter:              getEnv("TRACE_EXPORTER", "none"),
+		TraceExporterTarget:        getEnv("TRACE_EXPORTER_TARGET", ""),
+		OutboxRelayInterval:        getEnvDuration("OUTBOX_RELAY_INTERVAL", 5*time.Second),
+		OutboxMaxAttempts:          getEnvInt("OUTBOX_MAX_ATTEMPTS", 12),
+		OutboxRetention:            getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+		WebhookDeliveryInterval:    getEnvDuration("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second),
+		WebhookMaxAttempts:         getEnvInt("WEBHOOK_MAX_ATTEMPTS", 10),
+		WebhookTimeout:             getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		LegacyErrorResponses:       getEnvBool("LEGACY_ERROR_RESPONSES", true),
+		DevMode:                    getEnvBool("DEV_MODE", false),
 	}
 }
 
@@ -45,3 +99,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
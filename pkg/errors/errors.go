@@ -4,6 +4,13 @@ package errors
 import (
 	"errors"
 	"net/http"
+	"sort"
+
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 // ErrorType represents the type of an error
@@ -18,12 +25,21 @@ const (
 	InternalError   ErrorType = "INTERNAL_ERROR"
 )
 
-// AppError represents an application error
+// errorDomain identifies this service in the ErrorInfo details of status errors
+const errorDomain = "authentication.pharmakart.ca"
+
+// AppError represents an application error.
+// Message and Details are in English; Localize returns them in the client's language.
 type AppError struct {
-	Type    ErrorType         `json:"type"`
-	Message string            `json:"message"`
-	Details map[string]string `json:"details,omitempty"`
-	Status  int               `json:"-"` // HTTP status code, not returned to client
+	Type        ErrorType         `json:"type"`
+	MessageCode string            `json:"message_code,omitempty"` // catalog key of the message, stable for clients to rely on
+	Message     string            `json:"message"`
+	Details     map[string]string `json:"details,omitempty"`
+	DetailCodes map[string]string `json:"detail_codes,omitempty"` // catalog keys of the details, by field
+	Status      int               `json:"-"`                      // HTTP status code, not returned to client
+
+	message i18n.Message            // the message with its placeholder values, for localization
+	fields  map[string]i18n.Message // the details with their placeholder values
 }
 
 // Error returns the error message
@@ -31,75 +47,175 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+// WithParams sets the values of the message's placeholders, given as names followed by values
+func (e *AppError) WithParams(params ...string) *AppError {
+	e.message = i18n.M(e.message.Key, params...)
+	e.Message = i18n.Translate(i18n.English, e.message)
+	return e
+}
+
+// Localize returns the message and details in the language.
+// Text that is not in the catalogs, such as the cause of internal errors, is returned as it is.
+func (e *AppError) Localize(lang string) (string, map[string]string) {
+	message := e.Message
+	if e.message.Key != "" {
+		message = i18n.Translate(lang, e.message)
+	}
+
+	if len(e.Details) == 0 {
+		return message, e.Details
+	}
+	details := make(map[string]string, len(e.Details))
+	for field, text := range e.Details {
+		details[field] = text
+		if fieldMessage, ok := e.fields[field]; ok {
+			details[field] = i18n.Translate(lang, fieldMessage)
+		}
+	}
+	return message, details
+}
+
+// Code returns the gRPC status code of the error
+func (e *AppError) Code() codes.Code {
+	switch e.Type {
+	case ValidationError, BadRequestError:
+		return codes.InvalidArgument
+	case NotFoundError:
+		return codes.NotFound
+	case AuthError:
+		return codes.Unauthenticated
+	case ConflictError:
+		return codes.AlreadyExists
+	default:
+		return codes.Internal
+	}
+}
+
+// GRPCStatus returns the error as a gRPC status, so that returning an AppError from a handler sets the status code.
+// The codes of the message and details are attached as ErrorInfo metadata, under "code" and "field.<name>",
+// and the fields of validation errors as BadRequest field violations.
+func (e *AppError) GRPCStatus() *status.Status {
+	st := status.New(e.Code(), e.Message)
+
+	var details []protoadapt.MessageV1
+	if e.MessageCode != "" {
+		metadata := map[string]string{"code": e.MessageCode}
+		for field, code := range e.DetailCodes {
+			metadata["field."+field] = code
+		}
+		details = append(details, &errdetails.ErrorInfo{Reason: string(e.Type), Domain: errorDomain, Metadata: metadata})
+	}
+	if e.Type == ValidationError && len(e.Details) > 0 {
+		details = append(details, e.badRequest())
+	}
+	if len(details) == 0 {
+		return st
+	}
+
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// badRequest lists the details of a validation error as field violations, sorted by field
+func (e *AppError) badRequest() *errdetails.BadRequest {
+	fields := make([]string, 0, len(e.Details))
+	for field := range e.Details {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: e.Details[field],
+		})
+	}
+	return badRequest
+}
+
 // ValidationErrors represents multiple validation errors
 type ValidationErrors struct {
 	Fields map[string]string `json:"fields"`
 }
 
-// NewValidationError creates a new validation error for a single field
-func NewValidationError(field, message string) *AppError {
-	return &AppError{
-		Type:    ValidationError,
-		Message: "Validation failed",
-		Details: map[string]string{field: message},
-		Status:  http.StatusBadRequest,
+// newAppError creates an error whose message is a catalog key. Text that is not a key is kept as the message,
+// without a code.
+func newAppError(errorType ErrorType, key string, status int) *AppError {
+	err := &AppError{
+		Type:    errorType,
+		Message: i18n.Translate(i18n.English, i18n.M(key)),
+		Status:  status,
+		message: i18n.M(key),
+	}
+	if i18n.Has(key) {
+		err.MessageCode = key
 	}
+	return err
+}
+
+// NewValidationError creates a new validation error for a single field. The message is a catalog key.
+func NewValidationError(field, message string) *AppError {
+	return NewFieldErrors(map[string]i18n.Message{field: i18n.M(message)})
 }
 
-// NewValidationErrors creates a new validation error with multiple fields
+// NewValidationErrors creates a new validation error with multiple fields, whose messages are catalog keys
 func NewValidationErrors(fields map[string]string) *AppError {
-	return &AppError{
-		Type:    ValidationError,
-		Message: "Validation failed",
-		Details: fields,
-		Status:  http.StatusBadRequest,
+	messages := make(map[string]i18n.Message, len(fields))
+	for field, key := range fields {
+		messages[field] = i18n.M(key)
 	}
+	return NewFieldErrors(messages)
 }
 
-// NewAuthError creates a new authentication error
-func NewAuthError(message string) *AppError {
-	return &AppError{
-		Type:    AuthError,
-		Message: message,
-		Status:  http.StatusUnauthorized,
+// NewFieldError creates a new validation error for a single field whose message has placeholders
+func NewFieldError(field string, message i18n.Message) *AppError {
+	return NewFieldErrors(map[string]i18n.Message{field: message})
+}
+
+// NewFieldErrors creates a new validation error with multiple fields whose messages have placeholders
+func NewFieldErrors(fields map[string]i18n.Message) *AppError {
+	err := newAppError(ValidationError, "error.validation_failed", http.StatusBadRequest)
+	err.Details = make(map[string]string, len(fields))
+	err.DetailCodes = make(map[string]string, len(fields))
+	err.fields = fields
+	for field, message := range fields {
+		err.Details[field] = i18n.Translate(i18n.English, message)
+		if i18n.Has(message.Key) {
+			err.DetailCodes[field] = message.Key
+		}
 	}
+	return err
+}
+
+// NewAuthError creates a new authentication error. The message is a catalog key.
+func NewAuthError(message string) *AppError {
+	return newAppError(AuthError, message, http.StatusUnauthorized)
 }
 
-// NewNotFoundError creates a new not found error
+// NewNotFoundError creates a new not found error. The message is a catalog key.
 func NewNotFoundError(message string) *AppError {
-	return &AppError{
-		Type:    NotFoundError,
-		Message: message,
-		Status:  http.StatusNotFound,
-	}
+	return newAppError(NotFoundError, message, http.StatusNotFound)
 }
 
-// NewBadRequestError creates a new bad request error
+// NewBadRequestError creates a new bad request error. The message is a catalog key.
 func NewBadRequestError(message string) *AppError {
-	return &AppError{
-		Type:    BadRequestError,
-		Message: message,
-		Status:  http.StatusBadRequest,
-	}
+	return newAppError(BadRequestError, message, http.StatusBadRequest)
 }
 
-// NewConflictError creates a new conflict error
+// NewConflictError creates a new conflict error. The message is a catalog key.
 func NewConflictError(message string) *AppError {
-	return &AppError{
-		Type:    ConflictError,
-		Message: message,
-		Status:  http.StatusConflict,
-	}
+	return newAppError(ConflictError, message, http.StatusConflict)
 }
 
-// NewInternalError creates a new internal error
+// NewInternalError creates a new internal error. Its cause is kept in English under the internal detail.
 func NewInternalError(err error) *AppError {
-	return &AppError{
-		Type:    InternalError,
-		Message: "An internal error occurred",
-		Details: map[string]string{"internal": err.Error()},
-		Status:  http.StatusInternalServerError,
-	}
+	appErr := newAppError(InternalError, "error.internal", http.StatusInternalServerError)
+	appErr.Details = map[string]string{"internal": err.Error()}
+	return appErr
 }
 
 // IsAppError checks if an error is an AppError
@@ -0,0 +1,109 @@
+// Package i18n translates the messages returned to clients. Messages are identified by keys, such as
+// "validation.email_invalid", that are also returned to clients as stable, machine-readable codes.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	English = "en-CA"
+	French  = "fr-CA"
+
+	// DefaultLanguage is used for clients that accept none of the supported languages
+	DefaultLanguage = English
+)
+
+// Languages are the supported languages, in order of preference when the client has none
+var Languages = []string{English, French}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs maps each supported language to its messages by key
+var catalogs = map[string]map[string]string{}
+
+var matcher language.Matcher
+
+func init() {
+	tags := make([]language.Tag, 0, len(Languages))
+	for _, lang := range Languages {
+		data, err := locales.ReadFile(path.Join("locales", lang+".json"))
+		if err != nil {
+			panic("i18n: missing catalog for " + lang)
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic("i18n: invalid catalog for " + lang + ": " + err.Error())
+		}
+		catalogs[lang] = catalog
+		tags = append(tags, language.MustParse(lang))
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// Message is a catalog key with the values of its placeholders
+type Message struct {
+	Key    string
+	Params map[string]string
+}
+
+// M returns the message with the given key. Params are placeholder names followed by their values,
+// e.g. M("role.not_found", "role", name) for the text `Role "{role}" not found`.
+func M(key string, params ...string) Message {
+	message := Message{Key: key}
+	if len(params) > 1 {
+		message.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			message.Params[params[i]] = params[i+1]
+		}
+	}
+	return message
+}
+
+// Has reports whether key is a message of the English catalog, which has every key
+func Has(key string) bool {
+	_, ok := catalogs[English][key]
+	return ok
+}
+
+// Translate returns the message in the language with its placeholders, such as {role}, replaced.
+// Messages missing from the language's catalog are given in English, and keys missing from every catalog
+// are returned as they are, so that text which is not a key passes through unchanged.
+func Translate(lang string, message Message) string {
+	text, ok := catalogs[lang][message.Key]
+	if !ok {
+		text, ok = catalogs[English][message.Key]
+	}
+	if !ok {
+		return message.Key
+	}
+
+	if len(message.Params) == 0 {
+		return text
+	}
+	replacements := make([]string, 0, len(message.Params)*2)
+	for name, value := range message.Params {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
+
+// Match returns the supported language that best matches an Accept-Language value, such as "fr-CA,fr;q=0.9,en;q=0.8".
+// Any French is answered in Canadian French; values that are invalid or match no language get DefaultLanguage.
+func Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLanguage
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLanguage
+	}
+	return Languages[index]
+}
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+)
+
+// Country holds the address and phone rules of a country. Register more countries with RegisterCountry.
+// Messages are i18n catalog keys, so a new country needs its messages added to every catalog.
+type Country struct {
+	Name    string   // stored with addresses, e.g. Canada
+	Code    string   // ISO 3166-1 alpha-2 code
+	Aliases []string // other accepted spellings, compared after normalization
+
+	RegionRequired string            // message for a missing region, e.g. "Province is required"
+	RegionUnknown  string            // message for an unknown region
+	Regions        map[string]string // normalized region names and abbreviations to region codes
+	PostalRequired string            // message for a missing postal code
+	PostalMismatch string            // message for a postal code not used in the region, with {postal_code} and {region}
+	PostalFormat   func(postalCode string) string
+	// PostalError returns the message of why a normalized postal code is invalid, or "" when it is valid
+	PostalError func(postalCode string) string
+	// PostalRegions returns the regions a normalized postal code is used in, or nil when unknown
+	PostalRegions func(postalCode string) []string
+
+	CallingCode string // e.g. 1
+	// NationalPhoneError returns why the digits of a phone number without country code are invalid,
+	// or a message without key when they are valid
+	NationalPhoneError func(digits string) i18n.Message
+}
+
+var countries = map[string]*Country{}
+
+// RegisterCountry adds the rules of a country, replacing any registered under the same name, code or alias
+func RegisterCountry(country *Country) {
+	for _, key := range append([]string{country.Name, country.Code}, country.Aliases...) {
+		countries[normalizeName(key)] = country
+	}
+}
+
+// LookupCountry returns the rules of a country given by name, code or alias
+func LookupCountry(name string) (*Country, bool) {
+	country, ok := countries[normalizeName(name)]
+	return country, ok
+}
+
+// SupportedCountries returns the names of the registered countries in alphabetical order
+func SupportedCountries() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, country := range countries {
+		if !seen[country.Name] {
+			seen[country.Name] = true
+			names = append(names, country.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeRegion maps a region name or abbreviation, such as "Ontario", "Ont." or "CA-ON", to its code
+func (c *Country) NormalizeRegion(region string) (code string, ok bool) {
+	key := strings.TrimPrefix(normalizeName(region), strings.ToLower(c.Code)+" ")
+	code, ok = c.Regions[key]
+	return code, ok
+}
+
+var accentReplacer = strings.NewReplacer("é", "e", "è", "e", "ê", "e", "É", "e", "î", "i", "Î", "i", "ô", "o")
+
+// normalizeName lowercases a name and removes accents, dots and extra spaces so that spellings can be compared
+func normalizeName(name string) string {
+	name = strings.ToLower(accentReplacer.Replace(strings.TrimSpace(name)))
+	name = strings.ReplaceAll(name, ".", "")
+	return strings.Join(strings.Fields(strings.ReplaceAll(name, "-", " ")), " ")
+}
+
+// NormalizeAddress returns the country's name, the region as a code and the postal code in the country's format.
+// Values that are not recognized are returned trimmed, for validation to report.
+func NormalizeAddress(region, postalCode, country string) (string, string, string) {
+	region = strings.TrimSpace(region)
+	postalCode = strings.TrimSpace(postalCode)
+
+	rules, ok := LookupCountry(country)
+	if !ok {
+		return region, postalCode, strings.TrimSpace(country)
+	}
+
+	if code, ok := rules.NormalizeRegion(region); ok {
+		region = code
+	}
+	return region, rules.PostalFormat(postalCode), rules.Name
+}
+
+// NormalizeProvince maps a Canadian province or territory name or abbreviation to its ISO 3166-2:CA code
+func NormalizeProvince(province string) (string, bool) {
+	return canada.NormalizeRegion(province)
+}
+
+// validateAddressFields records the validation errors of the region, postal code and country of an address
+func validateAddressFields(validationErrors map[string]i18n.Message, region, postalCode, country string) {
+	if strings.TrimSpace(country) == "" {
+		validationErrors["country"] = i18n.M("address.country_required")
+		return
+	}
+
+	rules, ok := LookupCountry(country)
+	if !ok {
+		validationErrors["country"] = i18n.M("address.country_unsupported", "country", strings.TrimSpace(country), "countries", strings.Join(SupportedCountries(), ", "))
+		return
+	}
+
+	code, regionOK := rules.NormalizeRegion(region)
+	if strings.TrimSpace(region) == "" {
+		validationErrors["province"] = i18n.M(rules.RegionRequired)
+	} else if !regionOK {
+		validationErrors["province"] = i18n.M(rules.RegionUnknown)
+	}
+
+	postalCode = rules.PostalFormat(postalCode)
+	if postalCode == "" {
+		validationErrors["postal_code"] = i18n.M(rules.PostalRequired)
+	} else if message := rules.PostalError(postalCode); message != "" {
+		validationErrors["postal_code"] = i18n.M(message)
+	}
+
+	// Check that the postal code is used in the region
+	if validationErrors["province"].Key != "" || validationErrors["postal_code"].Key != "" || rules.PostalRegions == nil {
+		return
+	}
+	regions := rules.PostalRegions(postalCode)
+	if regions == nil {
+		return
+	}
+	for _, r := range regions {
+		if r == code {
+			return
+		}
+	}
+	validationErrors["postal_code"] = i18n.M(rules.PostalMismatch, "postal_code", postalCode, "region", code)
+}
+
+// Canada
+
+var canada = &Country{
+	Name:           "Canada",
+	Code:           "CA",
+	Aliases:        []string{"CAN"},
+	RegionRequired: "address.province_required",
+	RegionUnknown:  "address.province_unknown",
+	Regions: map[string]string{
+		"ab": "AB", "alta": "AB", "alberta": "AB",
+		"bc": "BC", "british columbia": "BC", "colombie britannique": "BC",
+		"mb": "MB", "man": "MB", "manitoba": "MB",
+		"nb": "NB", "new brunswick": "NB", "nouveau brunswick": "NB",
+		"nl": "NL", "nf": "NL", "nfld": "NL", "newfoundland": "NL", "newfoundland and labrador": "NL", "terre neuve et labrador": "NL",
+		"ns": "NS", "nova scotia": "NS", "nouvelle ecosse": "NS",
+		"nt": "NT", "nwt": "NT", "northwest territories": "NT", "territoires du nord ouest": "NT",
+		"nu": "NU", "nunavut": "NU",
+		"on": "ON", "ont": "ON", "ontario": "ON",
+		"pe": "PE", "pei": "PE", "prince edward island": "PE", "ile du prince edouard": "PE",
+		"qc": "QC", "pq": "QC", "que": "QC", "quebec": "QC",
+		"sk": "SK", "sask": "SK", "saskatchewan": "SK",
+		"yt": "YT", "yk": "YT", "yukon": "YT", "yukon territory": "YT",
+	},
+	PostalRequired:     "address.postal_code_required",
+	PostalMismatch:     "address.postal_code_not_in_province",
+	PostalFormat:       formatCanadianPostalCode,
+	PostalError:        canadianPostalCodeError,
+	PostalRegions:      canadianPostalCodeRegions,
+	CallingCode:        "1",
+	NationalPhoneError: nanpPhoneError("phone.canadian_length", "phone.canadian_area_code", "(416) 555-0123"),
+}
+
+// canadianPostalCodeProvinces maps the first letter of a postal code to the provinces and territories it is used in
+var canadianPostalCodeProvinces = map[byte][]string{
+	'A': {"NL"},
+	'B': {"NS"},
+	'C': {"PE"},
+	'E': {"NB"},
+	'G': {"QC"}, 'H': {"QC"}, 'J': {"QC"},
+	'K': {"ON"}, 'L': {"ON"}, 'M': {"ON"}, 'N': {"ON"}, 'P': {"ON"},
+	'R': {"MB"},
+	'S': {"SK"},
+	'T': {"AB"},
+	'V': {"BC"},
+	'X': {"NT", "NU"},
+	'Y': {"YT"},
+}
+
+// canadianPostalCodePattern allows the letters used in Canadian postal codes. D, F, I, O, Q and U are never used,
+// and W and Z are not used as the first letter.
+var canadianPostalCodePattern = regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z] [0-9][ABCEGHJ-NPRSTV-Z][0-9]$`)
+
+// formatCanadianPostalCode uppercases a postal code and formats it as "A1A 1A1"
+func formatCanadianPostalCode(postalCode string) string {
+	compact := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(postalCode, "-", "")), ""))
+	if len(compact) != 6 {
+		return strings.ToUpper(strings.TrimSpace(postalCode))
+	}
+	return compact[:3] + " " + compact[3:]
+}
+
+func canadianPostalCodeError(postalCode string) string {
+	switch {
+	case !regexp.MustCompile(`^[A-Z][0-9][A-Z] [0-9][A-Z][0-9]$`).MatchString(postalCode):
+		return "address.postal_code_format"
+	case strings.ContainsAny(postalCode[:1], "DFIOQUWZ"):
+		return "address.postal_code_first_letter"
+	case !canadianPostalCodePattern.MatchString(postalCode):
+		return "address.postal_code_letters"
+	}
+	return ""
+}
+
+func canadianPostalCodeRegions(postalCode string) []string {
+	return canadianPostalCodeProvinces[postalCode[0]]
+}
+
+// United States
+
+var unitedStates = &Country{
+	Name:           "United States",
+	Code:           "US",
+	Aliases:        []string{"USA", "United States of America", "U.S.", "U.S.A.", "États-Unis", "Etats Unis"},
+	RegionRequired: "address.state_required",
+	RegionUnknown:  "address.state_unknown",
+	Regions:        usStates(),
+	PostalRequired: "address.zip_code_required",
+	PostalMismatch: "address.zip_code_not_in_state",
+	PostalFormat: func(postalCode string) string {
+		compact := strings.Join(strings.Fields(strings.ReplaceAll(postalCode, "-", "")), "")
+		if len(compact) == 9 {
+			return compact[:5] + "-" + compact[5:]
+		}
+		return compact
+	},
+	PostalError: func(postalCode string) string {
+		if !regexp.MustCompile(`^[0-9]{5}(-[0-9]{4})?$`).MatchString(postalCode) {
+			return "address.zip_code_format"
+		}
+		return ""
+	},
+	CallingCode:        "1",
+	NationalPhoneError: nanpPhoneError("phone.us_length", "phone.us_area_code", "(212) 555-0123"),
+}
+
+// usStates returns the states, the District of Columbia and the inhabited territories by name and USPS code
+func usStates() map[string]string {
+	names := map[string]string{
+		"AL": "alabama", "AK": "alaska", "AZ": "arizona", "AR": "arkansas", "CA": "california",
+		"CO": "colorado", "CT": "connecticut", "DE": "delaware", "DC": "district of columbia", "FL": "florida",
+		"GA": "georgia", "HI": "hawaii", "ID": "idaho", "IL": "illinois", "IN": "indiana",
+		"IA": "iowa", "KS": "kansas", "KY": "kentucky", "LA": "louisiana", "ME": "maine",
+		"MD": "maryland", "MA": "massachusetts", "MI": "michigan", "MN": "minnesota", "MS": "mississippi",
+		"MO": "missouri", "MT": "montana", "NE": "nebraska", "NV": "nevada", "NH": "new hampshire",
+		"NJ": "new jersey", "NM": "new mexico", "NY": "new york", "NC": "north carolina", "ND": "north dakota",
+		"OH": "ohio", "OK": "oklahoma", "OR": "oregon", "PA": "pennsylvania", "RI": "rhode island",
+		"SC": "south carolina", "SD": "south dakota", "TN": "tennessee", "TX": "texas", "UT": "utah",
+		"VT": "vermont", "VA": "virginia", "WA": "washington", "WV": "west virginia", "WI": "wisconsin",
+		"WY": "wyoming", "PR": "puerto rico", "GU": "guam", "VI": "us virgin islands", "AS": "american samoa",
+		"MP": "northern mariana islands",
+	}
+
+	regions := make(map[string]string, len(names)*2)
+	for code, name := range names {
+		regions[strings.ToLower(code)] = code
+		regions[name] = code
+	}
+	return regions
+}
+
+func init() {
+	RegisterCountry(canada)
+	RegisterCountry(unitedStates)
+}
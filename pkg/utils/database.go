@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"github.com/PharmaKart/authentication-svc/internal/models"
 	"github.com/PharmaKart/authentication-svc/pkg/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -14,3 +15,149 @@ func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
 	}
 	return db, nil
 }
+
+// MigrateDB creates missing tables and columns for the service's models
+func MigrateDB(db *gorm.DB) error {
+	// Roles used to be limited to customer and admin by a check constraint
+	if db.Migrator().HasConstraint(&models.User{}, "chk_users_role") {
+		if err := db.Migrator().DropConstraint(&models.User{}, "chk_users_role"); err != nil {
+			return err
+		}
+	}
+
+	err := db.AutoMigrate(
+		&models.User{},
+		&models.Customer{},
+		&models.SigningKey{},
+		&models.Permission{},
+		&models.Role{},
+		&models.UserRole{},
+		&models.AuthorizationDecision{},
+		&models.Invitation{},
+		&models.ProfessionalCredential{},
+		&models.Dependent{},
+		&models.DelegationGrant{},
+		&models.Address{},
+		&models.AccountDeletion{},
+		&models.AuditEvent{},
+		&models.DataExport{},
+		&models.PolicyDocument{},
+		&models.Consent{},
+		&models.OutboxEvent{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.WebhookDeadLetter{},
+	)
+	if err != nil {
+		return err
+	}
+
+	// A customer has at most one default shipping and one default billing address
+	for _, stmt := range []string{
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_default_shipping ON addresses (customer_id) WHERE is_default_shipping",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_default_billing ON addresses (customer_id) WHERE is_default_billing",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := migrateCustomerAddresses(db); err != nil {
+		return err
+	}
+
+	return migrateAuditChain(db)
+}
+
+// migrateAuditChain links audit events recorded before the chain existed, oldest first,
+// and makes the audit table append-only
+func migrateAuditChain(db *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var unchained []models.AuditEvent
+		err := tx.Select("id", "action", "actor_id", "subject_id", "details", "created_at").
+			Where("sequence IS NULL").Order("created_at ASC, id ASC").Find(&unchained).Error
+		if err != nil || len(unchained) == 0 {
+			return err
+		}
+
+		var last models.AuditEvent
+		if err := tx.Where("sequence IS NOT NULL").Order("sequence DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		prevHash := last.Hash
+		if last.Sequence == 0 {
+			prevHash = models.AuditGenesisHash
+		}
+
+		sequence := last.Sequence
+		for _, event := range unchained {
+			sequence++
+			event.Sequence = sequence
+			event.PrevHash = prevHash
+			hash, err := event.ComputeHash()
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&models.AuditEvent{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+				"sequence":  event.Sequence,
+				"prev_hash": event.PrevHash,
+				"hash":      hash,
+			}).Error
+			if err != nil {
+				return err
+			}
+			prevHash = hash
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Reject updates and deletes of audit events, including ones made outside the service
+	for _, stmt := range []string{
+		`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_events is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events",
+		"CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events FOR EACH ROW EXECUTE FUNCTION audit_events_append_only()",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addressColumns are the address columns customers had before addresses got their own table
+var addressColumns = []string{"street_line1", "street_line2", "city", "province", "postal_code", "country"}
+
+// migrateCustomerAddresses moves the address stored on each customer row into a default "Home" address
+// and drops the old columns
+func migrateCustomerAddresses(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Customer{}, "street_line1") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO addresses (id, customer_id, label, street_line1, street_line2, city, province, postal_code, country,
+				is_default_shipping, is_default_billing, created_at, updated_at)
+			SELECT uuid_generate_v4(), c.id, 'Home', c.street_line1, c.street_line2, c.city, c.province, c.postal_code, c.country,
+				true, true, c.created_at, now()
+			FROM customers c
+			WHERE NOT EXISTS (SELECT 1 FROM addresses a WHERE a.customer_id = c.id)`).Error
+		if err != nil {
+			return err
+		}
+
+		for _, column := range addressColumns {
+			if err := tx.Migrator().DropColumn(&models.Customer{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -28,3 +29,18 @@ func Warn(message string, fields map[string]interface{}) {
 func Error(message string, fields map[string]interface{}) {
 	Logger.WithFields(fields).Error(message)
 }
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger, such as one with the request ID of a call
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext returns the logger carried by ctx, or the global logger when it carries none
+func LoggerFromContext(ctx context.Context) *logrus.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+		return logger
+	}
+	return logrus.NewEntry(Logger)
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
+)
+
+// defaultPhoneCountry is used for phone numbers without a country code when the customer's country is unknown
+const defaultPhoneCountry = "Canada"
+
+// NormalizePhone converts a phone number to E.164, e.g. "+14165550123". Numbers starting with "+", "00" or "011"
+// carry their country code; other numbers are read as national numbers of the given country.
+// It returns the reason the number is invalid when it cannot be converted, and a message without key otherwise.
+func NormalizePhone(phone, country string) (string, i18n.Message) {
+	rules, ok := LookupCountry(country)
+	if !ok {
+		rules, _ = LookupCountry(defaultPhoneCountry)
+	}
+
+	// Drop the separators of common input formats, such as "(416) 555-0123" or "416.555.0123"
+	compact := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')', '/':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+
+	international := false
+	switch {
+	case strings.HasPrefix(compact, "+"):
+		compact, international = compact[1:], true
+	case strings.HasPrefix(compact, "011"):
+		compact, international = compact[3:], true
+	case strings.HasPrefix(compact, "00"):
+		compact, international = compact[2:], true
+	}
+
+	if compact == "" || strings.Trim(compact, "0123456789") != "" {
+		return "", i18n.M("phone.characters")
+	}
+
+	if !international {
+		// Accept a national number written with the country code but without "+", e.g. "1 416 555 0123"
+		if strings.HasPrefix(compact, rules.CallingCode) && rules.NationalPhoneError(compact).Key != "" &&
+			rules.NationalPhoneError(compact[len(rules.CallingCode):]).Key == "" {
+			compact = compact[len(rules.CallingCode):]
+		}
+		if message := rules.NationalPhoneError(compact); message.Key != "" {
+			return "", message
+		}
+		return "+" + rules.CallingCode + compact, i18n.Message{}
+	}
+
+	// North American numbers get the full national checks
+	if strings.HasPrefix(compact, "1") {
+		if message := nanpPhoneError("phone.nanp_length", "phone.nanp_area_code", "+1 416 555 0123")(compact[1:]); message.Key != "" {
+			return "", message
+		}
+		return "+" + compact, i18n.Message{}
+	}
+
+	// E.164 numbers have at most 15 digits, and no country has numbers shorter than 8 with its code
+	if len(compact) < 8 || len(compact) > 15 || compact[0] == '0' {
+		return "", i18n.M("phone.international")
+	}
+	return "+" + compact, i18n.Message{}
+}
+
+// nanpPhoneError returns a check for the 10-digit national numbers of the North American Numbering Plan,
+// with the messages of the country for a wrong length, which get the example number, and for a wrong area code
+func nanpPhoneError(lengthKey, areaCodeKey, example string) func(digits string) i18n.Message {
+	return func(digits string) i18n.Message {
+		if len(digits) != 10 {
+			return i18n.M(lengthKey, "example", example)
+		}
+		if digits[0] < '2' || digits[3] < '2' {
+			return i18n.M(areaCodeKey)
+		}
+		return i18n.Message{}
+	}
+}
+
+// validatePhone records why a phone number is invalid for the country, if it is
+func validatePhone(validationErrors map[string]i18n.Message, phone, country string) {
+	if strings.TrimSpace(phone) == "" {
+		validationErrors["phone"] = i18n.M("phone.required")
+		return
+	}
+	if _, message := NormalizePhone(phone, country); message.Key != "" {
+		validationErrors["phone"] = message
+	}
+}
@@ -1,75 +1,233 @@
 package utils
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
+	"github.com/PharmaKart/authentication-svc/internal/metrics"
 	"github.com/PharmaKart/authentication-svc/internal/proto"
+	"github.com/PharmaKart/authentication-svc/internal/tracing"
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashPassword(password string) (string, error) {
+func HashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "utils.HashPassword")
+	start := time.Now()
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	metrics.ObservePasswordHash("hash", time.Since(start))
+	tracing.End(span, err)
 	if err != nil {
 		return "", err
 	}
 	return string(hashedPassword), nil
 }
 
-func CheckPasswordHash(password, hash string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+func CheckPasswordHash(ctx context.Context, password, hash string) error {
+	_, span := tracing.Start(ctx, "utils.CheckPasswordHash")
+	start := time.Now()
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	metrics.ObservePasswordHash("compare", time.Since(start))
+	// A mismatch is the answer to the check rather than a failure, so it is not recorded on the span
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		span.End()
+		return err
+	}
+	tracing.End(span, err)
+	return err
+}
+
+// TokenClaims are the claims carried by an access token
+type TokenClaims struct {
+	UserID      string
+	Role        string   // primary role
+	Roles       []string // every role held by the user
+	Permissions []string // permissions resolved from Roles
+	StoreID     string   // store of staff members, empty for customers
+	LicensedIn  []string // provinces the user holds a verified, unexpired license in
+	AgeOver     []int    // age thresholds the user has reached, e.g. 16, 18, 19
+	Subject     string   // user or dependent the token acts for, set on acting tokens
+	Actor       string   // user actually acting on behalf of Subject, set on acting tokens
+	ConsentOnly bool     // only good for accepting the required policies, issued by Login until they are accepted
+	ExpiresAt   time.Time
 }
 
-func GenerateJWT(userID, role, secret string) (string, error) {
+// IsActing reports whether the token was issued to one user acting on behalf of another
+func (c *TokenClaims) IsActing() bool {
+	return c.Actor != ""
+}
+
+// TokenTTL is the lifetime of tokens signed without an expiry
+const TokenTTL = 24 * time.Hour
+
+// GenerateJWT signs a token for the user. When kid is set it is written to the token header
+// so the verifier can pick the matching signing key.
+func GenerateJWT(tokenClaims TokenClaims, kid, secret string) (string, error) {
 	// Create a new token object
 	token := jwt.New(jwt.SigningMethodHS256)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
 
 	// Set the claims
 	claims := token.Claims.(jwt.MapClaims)
-	claims["userid"] = userID
-	claims["role"] = role
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	claims["userid"] = tokenClaims.UserID
+	claims["role"] = tokenClaims.Role
+	claims["roles"] = tokenClaims.Roles
+	claims["perms"] = tokenClaims.Permissions
+	if tokenClaims.StoreID != "" {
+		claims["store_id"] = tokenClaims.StoreID
+	}
+	if len(tokenClaims.LicensedIn) > 0 {
+		claims["licensed_in"] = tokenClaims.LicensedIn
+	}
+	if len(tokenClaims.AgeOver) > 0 {
+		claims["age_over"] = tokenClaims.AgeOver
+	}
+	if tokenClaims.Subject != "" {
+		claims["sub"] = tokenClaims.Subject
+	}
+	if tokenClaims.Actor != "" {
+		claims["act"] = map[string]interface{}{"sub": tokenClaims.Actor}
+	}
+	if tokenClaims.ConsentOnly {
+		claims["scope"] = "consent"
+	}
+	if tokenClaims.ExpiresAt.IsZero() {
+		claims["exp"] = time.Now().Add(TokenTTL).Unix()
+	} else {
+		claims["exp"] = tokenClaims.ExpiresAt.Unix()
+	}
 
 	// Sign the token with the secret
 	return token.SignedString([]byte(secret))
 }
 
-func ValidateJWT(tokenString, secret string) (string, string, error) {
+// ValidateJWT verifies the token using the secret returned by getSecret for the token's kid header.
+// Tokens without a kid are passed an empty kid.
+func ValidateJWT(tokenString string, getSecret func(kid string) (string, error)) (*TokenClaims, error) {
 	// Parse the token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Check the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid token")
 		}
+		kid, _ := token.Header["kid"].(string)
+		secret, err := getSecret(kid)
+		if err != nil {
+			return nil, err
+		}
 		return []byte(secret), nil
 	})
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
 	// Check if the token is valid
 	if !token.Valid {
-		return "", "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	// Extract the claims
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	userID, ok := claims["userid"].(string)
 	if !ok {
-		return "", "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	role, ok := claims["role"].(string)
 	if !ok {
-		return "", "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+
+	// Tokens issued before roles were introduced only carry the primary role
+	roles := stringSlice(claims["roles"])
+	if roles == nil {
+		roles = []string{role}
+	}
+
+	storeID, _ := claims["store_id"].(string)
+	subject, _ := claims["sub"].(string)
+	scope, _ := claims["scope"].(string)
+
+	var actor string
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actor, _ = act["sub"].(string)
+	}
+
+	var expiresAt time.Time
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0)
+	}
+
+	return &TokenClaims{
+		UserID:      userID,
+		Role:        role,
+		Roles:       roles,
+		Permissions: stringSlice(claims["perms"]),
+		StoreID:     storeID,
+		LicensedIn:  stringSlice(claims["licensed_in"]),
+		AgeOver:     intSlice(claims["age_over"]),
+		Subject:     subject,
+		Actor:       actor,
+		ConsentOnly: scope == "consent",
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// stringSlice converts a JSON array claim to a string slice
+func stringSlice(claim interface{}) []string {
+	values, ok := claim.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
 	}
+	return result
+}
 
-	return userID, role, nil
+// intSlice converts a JSON array claim to an int slice
+func intSlice(claim interface{}) []int {
+	values, ok := claim.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]int, 0, len(values))
+	for _, value := range values {
+		if n, ok := value.(float64); ok {
+			result = append(result, int(n))
+		}
+	}
+	return result
+}
+
+// HashToken returns the hex SHA-256 of a secret token so that only its hash needs to be stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRandomHex returns n random bytes encoded as a hex string
+func GenerateRandomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 func ConvertMapToKeyValuePairs(m map[string]string) []*proto.KeyValuePair {
@@ -2,81 +2,332 @@ package utils
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
 	"github.com/PharmaKart/authentication-svc/pkg/errors"
+	"github.com/PharmaKart/authentication-svc/pkg/i18n"
 )
 
-func ValidateUserInput(username, email, password, firstName, lastName, phone, dob, billing1, city, province, postalCode, country string) error {
-	validationErrors := make(map[string]string)
+// ValidateUserInput validates a customer registration. Customers must be at least minAge years old.
+func ValidateUserInput(username, email, password, firstName, lastName, phone, dob, billing1, city, province, postalCode, country string, minAge int) error {
+	validationErrors := make(map[string]i18n.Message)
 
-	if strings.TrimSpace(username) == "" {
-		validationErrors["username"] = "Username is required"
+	validateAccountFields(validationErrors, username, email, password)
+
+	validateProfileField(validationErrors, "first_name", firstName)
+	validateProfileField(validationErrors, "last_name", lastName)
+	validatePhone(validationErrors, phone, country)
+
+	if strings.TrimSpace(dob) == "" {
+		validationErrors["date_of_birth"] = i18n.M("validation.date_of_birth_required")
+	} else if !isValidDOB(dob) {
+		validationErrors["date_of_birth"] = i18n.M("validation.date_of_birth_invalid")
+	} else if !isOldEnough(dob, minAge) {
+		validationErrors["date_of_birth"] = i18n.M("validation.date_of_birth_min_age", "min_age", strconv.Itoa(minAge))
+	}
+
+	validateProfileField(validationErrors, "street_line1", billing1)
+	validateProfileField(validationErrors, "city", city)
+	validateAddressFields(validationErrors, province, postalCode, country)
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
 	}
 
+	return nil
+}
+
+// UpdatableProfileFields are the customer profile fields that can be changed after registration.
+// Addresses are managed separately.
+var UpdatableProfileFields = map[string]bool{
+	"first_name": true,
+	"last_name":  true,
+	"phone":      true,
+}
+
+// ValidateProfileUpdate validates a partial profile update, keyed by field name,
+// with the same rules as ValidateUserInput
+func ValidateProfileUpdate(fields map[string]string) error {
+	validationErrors := make(map[string]i18n.Message)
+
+	if len(fields) == 0 {
+		validationErrors["update_mask"] = i18n.M("validation.update_mask_empty")
+	}
+
+	for field, value := range fields {
+		if !UpdatableProfileFields[field] {
+			validationErrors["update_mask"] = i18n.M("validation.update_mask_field", "field", field)
+			continue
+		}
+		validateProfileField(validationErrors, field, value)
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
+	}
+
+	return nil
+}
+
+// ValidateAddressInput validates a customer address with the same rules as ValidateUserInput
+func ValidateAddressInput(label, streetLine1, city, province, postalCode, country string) error {
+	validationErrors := make(map[string]i18n.Message)
+
+	if strings.TrimSpace(label) == "" {
+		validationErrors["label"] = i18n.M("validation.label_required")
+	} else if len(label) > 50 {
+		validationErrors["label"] = i18n.M("validation.label_too_long")
+	}
+
+	validateProfileField(validationErrors, "street_line1", streetLine1)
+	validateProfileField(validationErrors, "city", city)
+	validateAddressFields(validationErrors, province, postalCode, country)
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
+	}
+
+	return nil
+}
+
+// validateProfileField records the validation error of a customer profile field, if any
+func validateProfileField(validationErrors map[string]i18n.Message, field, value string) {
+	switch field {
+	case "first_name":
+		if strings.TrimSpace(value) == "" {
+			validationErrors[field] = i18n.M("validation.first_name_required")
+		}
+	case "last_name":
+		if strings.TrimSpace(value) == "" {
+			validationErrors[field] = i18n.M("validation.last_name_required")
+		}
+	case "phone":
+		validatePhone(validationErrors, value, "")
+	case "street_line1":
+		if strings.TrimSpace(value) == "" {
+			validationErrors[field] = i18n.M("validation.street_line1_required")
+		}
+	case "city":
+		if strings.TrimSpace(value) == "" {
+			validationErrors[field] = i18n.M("validation.city_required")
+		}
+	}
+}
+
+// ValidateAccountInput validates the login fields of a user that has no customer profile
+func ValidateAccountInput(username, email, password, role string) error {
+	validationErrors := make(map[string]i18n.Message)
+
+	validateAccountFields(validationErrors, username, email, password)
+
+	if strings.TrimSpace(role) == "" {
+		validationErrors["role"] = i18n.M("validation.role_required")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
+	}
+
+	return nil
+}
+
+// ValidateUserUpdate validates the account fields that are being changed.
+// Nil fields are left unchanged and not validated.
+func ValidateUserUpdate(username, email, role *string) error {
+	validationErrors := make(map[string]i18n.Message)
+
+	if username != nil && strings.TrimSpace(*username) == "" {
+		validationErrors["username"] = i18n.M("validation.username_required")
+	}
+
+	if email != nil {
+		if strings.TrimSpace(*email) == "" {
+			validationErrors["email"] = i18n.M("validation.email_required")
+		} else if !isValidEmail(*email) {
+			validationErrors["email"] = i18n.M("validation.email_invalid")
+		}
+	}
+
+	if role != nil && strings.TrimSpace(*role) == "" {
+		validationErrors["role"] = i18n.M("validation.role_required")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
+	}
+
+	return nil
+}
+
+// ValidateInvitationInput validates the email and role of a staff invitation
+func ValidateInvitationInput(email, role string) error {
+	validationErrors := make(map[string]i18n.Message)
+
 	if strings.TrimSpace(email) == "" {
-		validationErrors["email"] = "Email is required"
+		validationErrors["email"] = i18n.M("validation.email_required")
 	} else if !isValidEmail(email) {
-		validationErrors["email"] = "Invalid email format"
+		validationErrors["email"] = i18n.M("validation.email_invalid")
 	}
 
-	if strings.TrimSpace(password) == "" {
-		validationErrors["password"] = "Password is required"
-	} else if !isValidPassword(password) {
-		validationErrors["password"] = "Password must be at least 8 characters long and contain an uppercase letter, a lowercase letter, a digit, and a special character"
+	if strings.TrimSpace(role) == "" {
+		validationErrors["role"] = i18n.M("validation.role_required")
+	} else if role == "customer" {
+		validationErrors["role"] = i18n.M("validation.role_customer_invitation")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
+	}
+
+	return nil
+}
+
+// ValidateCredentialInput validates a professional license submission
+func ValidateCredentialInput(licenseNumber, province, expiresOn string) error {
+	validationErrors := make(map[string]i18n.Message)
+
+	if strings.TrimSpace(licenseNumber) == "" {
+		validationErrors["license_number"] = i18n.M("validation.license_number_required")
+	} else if !regexp.MustCompile(`^[A-Za-z0-9-]{3,50}$`).MatchString(licenseNumber) {
+		validationErrors["license_number"] = i18n.M("validation.license_number_invalid")
+	}
+
+	if strings.TrimSpace(province) == "" {
+		validationErrors["issuing_province"] = i18n.M("validation.issuing_province_required")
+	} else if !IsValidProvinceCode(province) {
+		validationErrors["issuing_province"] = i18n.M("validation.issuing_province_invalid")
+	}
+
+	if strings.TrimSpace(expiresOn) == "" {
+		validationErrors["expires_on"] = i18n.M("validation.expires_on_required")
+	} else if expiry, err := ParseDate(expiresOn); err != nil {
+		validationErrors["expires_on"] = i18n.M("validation.expires_on_format")
+	} else if expiry.Before(time.Now().Truncate(24 * time.Hour)) {
+		validationErrors["expires_on"] = i18n.M("validation.license_expired")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
 	}
 
+	return nil
+}
+
+// ValidateDependentInput validates the profile of a dependent. The date of birth is optional.
+func ValidateDependentInput(firstName, lastName, dob string) error {
+	validationErrors := make(map[string]i18n.Message)
+
 	if strings.TrimSpace(firstName) == "" {
-		validationErrors["first_name"] = "First name is required"
+		validationErrors["first_name"] = i18n.M("validation.first_name_required")
 	}
 
 	if strings.TrimSpace(lastName) == "" {
-		validationErrors["last_name"] = "Last name is required"
+		validationErrors["last_name"] = i18n.M("validation.last_name_required")
 	}
 
-	if strings.TrimSpace(phone) == "" {
-		validationErrors["phone"] = "Phone number is required"
-	} else if !isValidPhone(phone) {
-		validationErrors["phone"] = "Invalid phone number format"
+	if strings.TrimSpace(dob) != "" && !isValidDOB(dob) {
+		validationErrors["date_of_birth"] = i18n.M("validation.date_of_birth_invalid")
 	}
 
-	if strings.TrimSpace(dob) == "" {
-		validationErrors["date_of_birth"] = "Date of birth is required"
-	} else if !isValidDOB(dob) {
-		validationErrors["date_of_birth"] = "Invalid date of birth or must be in the past"
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
 	}
 
-	if strings.TrimSpace(billing1) == "" {
-		validationErrors["street_line1"] = "Billing address line 1 is required"
+	return nil
+}
+
+// ValidateDelegationInput validates the scopes and optional RFC 3339 expiry of a delegation grant
+func ValidateDelegationInput(scopes, delegable []string, expiresAt string) error {
+	validationErrors := make(map[string]i18n.Message)
+
+	if len(scopes) == 0 {
+		validationErrors["scopes"] = i18n.M("validation.scopes_required")
+	}
+	for _, scope := range scopes {
+		if !contains(delegable, scope) {
+			validationErrors["scopes"] = i18n.M("validation.scope_not_delegable", "scope", scope)
+			break
+		}
 	}
 
-	if strings.TrimSpace(city) == "" {
-		validationErrors["city"] = "City is required"
+	if strings.TrimSpace(expiresAt) != "" {
+		if expiry, err := time.Parse(time.RFC3339, expiresAt); err != nil {
+			validationErrors["expires_at"] = i18n.M("validation.expires_at_format")
+		} else if !expiry.After(time.Now()) {
+			validationErrors["expires_at"] = i18n.M("validation.expires_at_past")
+		}
 	}
 
-	if strings.TrimSpace(province) == "" {
-		validationErrors["province"] = "Province is required"
+	if len(validationErrors) > 0 {
+		return errors.NewFieldErrors(validationErrors)
 	}
 
-	if strings.TrimSpace(postalCode) == "" {
-		validationErrors["postal_code"] = "Postal code is required"
-	} else if !regexp.MustCompile(`^[A-Z][0-9][A-Z] [0-9][A-Z][0-9]$`).MatchString(postalCode) {
-		validationErrors["postal_code"] = "Invalid postal code format"
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
+}
 
-	if strings.TrimSpace(country) == "" {
-		validationErrors["country"] = "Country is required"
+// IsValidProvinceCode reports whether code is a Canadian province or territory code
+func IsValidProvinceCode(code string) bool {
+	switch code {
+	case "AB", "BC", "MB", "NB", "NL", "NS", "NT", "NU", "ON", "PE", "QC", "SK", "YT":
+		return true
 	}
+	return false
+}
 
-	if len(validationErrors) > 0 {
-		return errors.NewValidationErrors(validationErrors)
+// ValidateRoleName validates the name of a new role
+func ValidateRoleName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.NewValidationError("name", "validation.role_name_required")
+	}
+	if !regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`).MatchString(name) {
+		return errors.NewValidationError("name", "validation.role_name_invalid")
 	}
+	return nil
+}
 
+// ValidatePassword validates a new password
+func ValidatePassword(password string) error {
+	if strings.TrimSpace(password) == "" {
+		return errors.NewValidationError("password", "validation.password_required")
+	}
+	if !isValidPassword(password) {
+		return errors.NewValidationError("password", passwordRequirements)
+	}
 	return nil
 }
 
+const passwordRequirements = "validation.password_requirements"
+
+func validateAccountFields(validationErrors map[string]i18n.Message, username, email, password string) {
+	if strings.TrimSpace(username) == "" {
+		validationErrors["username"] = i18n.M("validation.username_required")
+	}
+
+	if strings.TrimSpace(email) == "" {
+		validationErrors["email"] = i18n.M("validation.email_required")
+	} else if !isValidEmail(email) {
+		validationErrors["email"] = i18n.M("validation.email_invalid")
+	}
+
+	if strings.TrimSpace(password) == "" {
+		validationErrors["password"] = i18n.M("validation.password_required")
+	} else if !isValidPassword(password) {
+		validationErrors["password"] = i18n.M(passwordRequirements)
+	}
+}
+
 func isValidEmail(email string) bool {
 	re := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	return re.MatchString(email)
@@ -106,11 +357,6 @@ func isValidPassword(password string) bool {
 	return hasUpper && hasLower && hasDigit && hasSpecial
 }
 
-func isValidPhone(phone string) bool {
-	re := regexp.MustCompile(`^\+1\s\(\d{3}\)\s\d{3}\-\d{4}$`)
-	return re.MatchString(phone)
-}
-
 func isValidDOB(dob string) bool {
 	parsedDOB, err := time.Parse(time.RFC3339, dob)
 	if err != nil {
@@ -119,6 +365,20 @@ func isValidDOB(dob string) bool {
 	return parsedDOB.Before(time.Now())
 }
 
+// isOldEnough reports whether someone born on dob has turned minAge today
+func isOldEnough(dob string, minAge int) bool {
+	parsedDOB, err := ParseDOB(dob)
+	if err != nil {
+		return false
+	}
+	return !parsedDOB.AddDate(minAge, 0, 0).After(time.Now())
+}
+
 func ParseDOB(dob string) (time.Time, error) {
 	return time.Parse(time.RFC3339, dob)
 }
+
+// ParseDate parses a calendar date in YYYY-MM-DD format
+func ParseDate(date string) (time.Time, error) {
+	return time.Parse("2006-01-02", date)
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	goerrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/PharmaKart/authentication-svc/pkg/errors"
+)
+
+func TestValidateProfileUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   map[string]string // the catalog key of each field error
+	}{
+		{
+			name:   "valid update",
+			fields: map[string]string{"first_name": "Jane", "last_name": "Doe", "phone": "(416) 555-0123"},
+		},
+		{
+			name:   "single field",
+			fields: map[string]string{"last_name": "Smith"},
+		},
+		{
+			name: "empty mask",
+			want: map[string]string{"update_mask": "validation.update_mask_empty"},
+		},
+		{
+			name:   "field that cannot be updated",
+			fields: map[string]string{"email": "jane@example.com"},
+			want:   map[string]string{"update_mask": "validation.update_mask_field"},
+		},
+		{
+			name:   "address field",
+			fields: map[string]string{"first_name": "Jane", "city": "Toronto"},
+			want:   map[string]string{"update_mask": "validation.update_mask_field"},
+		},
+		{
+			name:   "blank first name",
+			fields: map[string]string{"first_name": "  "},
+			want:   map[string]string{"first_name": "validation.first_name_required"},
+		},
+		{
+			name:   "blank last name",
+			fields: map[string]string{"last_name": ""},
+			want:   map[string]string{"last_name": "validation.last_name_required"},
+		},
+		{
+			name:   "blank phone",
+			fields: map[string]string{"phone": ""},
+			want:   map[string]string{"phone": "phone.required"},
+		},
+		{
+			name:   "phone with letters",
+			fields: map[string]string{"phone": "416-CALL-NOW"},
+			want:   map[string]string{"phone": "phone.characters"},
+		},
+		{
+			name:   "every field invalid",
+			fields: map[string]string{"first_name": "", "last_name": "", "phone": "", "username": "jane"},
+			want: map[string]string{
+				"update_mask": "validation.update_mask_field",
+				"first_name":  "validation.first_name_required",
+				"last_name":   "validation.last_name_required",
+				"phone":       "phone.required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfileUpdate(tt.fields)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateProfileUpdate() error = %v", err)
+				}
+				return
+			}
+
+			var appErr *errors.AppError
+			if !goerrors.As(err, &appErr) {
+				t.Fatalf("ValidateProfileUpdate() error = %v, want an AppError", err)
+			}
+			if appErr.Type != errors.ValidationError {
+				t.Fatalf("ValidateProfileUpdate() error type = %s, want %s", appErr.Type, errors.ValidationError)
+			}
+			if !reflect.DeepEqual(appErr.DetailCodes, tt.want) {
+				t.Fatalf("ValidateProfileUpdate() detail codes = %v, want %v", appErr.DetailCodes, tt.want)
+			}
+		})
+	}
+}